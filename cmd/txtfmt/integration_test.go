@@ -302,6 +302,28 @@ func TestCLIUnsupportedCharsetReturns2(t *testing.T) {
 	}
 }
 
+func TestCLIMarkdownInputKeepsSyntax(t *testing.T) {
+	input := "# Глава \"один\"\n\nЭто \"тест\" - проверка [ссылки \"x\"](https://ex.com/a--b) и `a -- \"b\"`.\n> цитата...\n\n```\nx := \"a\" - b\n```\n\n| a - b |\n|---|\n"
+	want := "# Глава «один»\n\nЭто «тест» — проверка [ссылки «x»](https://ex.com/a--b) и `a -- \"b\"`.\n> цитата…\n\n```\nx := \"a\" - b\n```\n\n| a - b |\n|---|\n"
+	stdout, stderr, code := runCLI(t, []string{"-lang", "ru", "-input-format", "markdown", "-input", "-"}, input)
+	if code != 0 {
+		t.Fatalf("expected code 0, got %d stderr=%q", code, stderr)
+	}
+	if stdout != want {
+		t.Fatalf("unexpected stdout:\n%s", stdout)
+	}
+}
+
+func TestCLIMarkdownInputRejectsHTMLFormat(t *testing.T) {
+	_, stderr, code := runCLI(t, []string{"-input-format", "markdown", "-format", "html", "-input", "-"}, "text")
+	if code != 1 {
+		t.Fatalf("expected go run exit code 1 for app code 2, got %d", code)
+	}
+	if !strings.Contains(stderr, "-input-format markdown") {
+		t.Fatalf("unexpected stderr: %q", stderr)
+	}
+}
+
 func runCLI(t *testing.T, args []string, stdin string) (string, string, int) {
 	t.Helper()
 	stdout, stderr, code := runCLIBytes(t, args, []byte(stdin))
@@ -34,8 +34,20 @@ func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
 	lang := fs.String("lang", langAuto, "language: auto|en|ru|ua")
 	inner := fs.String("inner-quotes", "", "inner quote style: german|english|guillemets")
 	nbsp := fs.Bool("nbsp", false, "enable NBSP transformations")
+	symbols := fs.Bool("symbols", false, "enable symbol replacements: (c), (tm), +-, 3x4, ->, 1/2, 5'10\"")
+	initialsSpace := fs.String("initials-space", string(config.InitialsSpaceNBSP), "space between initials with -nbsp: nbsp|thin")
+	dialogueStyleRaw := fs.String("dialogue-style", string(config.DialogueStyleKeep), "dialogue style: dash|quotes|keep")
+	splitInlineDialogue := fs.Bool("split-inline-dialogue", false, "move speech after a colon into its own dialogue turn")
+	sceneBreak := fs.String("scene-break", "", "scene break marker: keep|<string>")
+	sceneBreakClass := fs.String("scene-break-class", "", "HTML class for scene breaks")
+	contentsPagesRaw := fs.String("contents-pages", printer.ContentsPagesKeep, "contents page numbers: keep|omit")
+	htmlSections := fs.Bool("html-sections", false, "wrap every chapter in <section> in html output")
+	frontMatter := fs.Bool("front-matter", false, "collect the leading title and meta lines into front matter (markdown), <meta> (html) and <metadata> (xml)")
+	metaKeysRaw := fs.String("meta-keys", string(config.MetaKeysAny), "meta line keys: any|known|header")
+	tocRaw := fs.String("toc", string(config.TOCKeep), "table of contents: keep|generate|check")
 	dumpAST := fs.Bool("dump-ast", false, "print parsed AST to stderr as JSON")
 	format := fs.String("format", string(printer.FormatPlain), "output format: plain|markdown|html|xml")
+	inputFormatRaw := fs.String("input-format", string(config.InputFormatPlain), "input format: plain|markdown")
 	inputPath := fs.String("input", "", "input source: file path or '-' for stdin")
 	outputPath := fs.String("output", "", "output destination: file path or '-' for stdout")
 	inputCharset := fs.String("input-charset", "utf-8", "input charset (utf-8|cp1251|koi8-r|koi8-u|cp866|iso-8859-5|mac-cyrillic)")
@@ -70,6 +82,46 @@ func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
 		return 2
 	}
 
+	contentsPages, err := printer.ParseContentsPages(*contentsPagesRaw)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err.Error())
+		return 2
+	}
+
+	inputFormat, err := config.ParseInputFormat(*inputFormatRaw)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err.Error())
+		return 2
+	}
+	if inputFormat == config.InputFormatMarkdown && outputFormat != printer.FormatPlain && outputFormat != printer.FormatMarkdown {
+		_, _ = fmt.Fprintf(stderr, "unsupported -format value %q with -input-format markdown (expected plain|markdown)\n", *format)
+		return 2
+	}
+
+	initials, err := config.ParseInitialsSpace(*initialsSpace)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err.Error())
+		return 2
+	}
+
+	dialogueStyle, err := config.ParseDialogueStyle(*dialogueStyleRaw)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err.Error())
+		return 2
+	}
+
+	metaKeys, err := config.ParseMetaKeys(*metaKeysRaw)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err.Error())
+		return 2
+	}
+
+	toc, err := config.ParseTOCMode(*tocRaw)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err.Error())
+		return 2
+	}
+
 	inputRaw, err := readInput(*inputPath, stdin)
 	if err != nil {
 		_, _ = fmt.Fprintln(stderr, err.Error())
@@ -90,6 +142,13 @@ func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
 		_, _ = fmt.Fprintln(stderr, err.Error())
 		return 2
 	}
+	cfg.Symbols = *symbols
+	cfg.InitialsSpace = initials
+	cfg.InputFormat = inputFormat
+	cfg.DialogueStyle = dialogueStyle
+	cfg.SplitInlineDialogue = *splitInlineDialogue
+	cfg.MetaKeys = metaKeys
+	cfg.TOC = toc
 
 	doc := parser.Parse(input, cfg)
 	if *dumpAST {
@@ -100,7 +159,13 @@ func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
 	}
 	rewrite.Apply(&doc, cfg)
 
-	output, err := charset.Encode(printer.PrintWithFormat(doc, outputFormat), *outputCharset)
+	output, err := charset.Encode(printer.PrintWithOptions(doc, outputFormat, printer.Options{
+		SceneBreak:      strings.TrimSpace(*sceneBreak),
+		SceneBreakClass: strings.TrimSpace(*sceneBreakClass),
+		FrontMatter:     *frontMatter,
+		ContentsPages:   contentsPages,
+		HTMLSections:    *htmlSections,
+	}), *outputCharset)
 	if err != nil {
 		_, _ = fmt.Fprintln(stderr, err.Error())
 		return 1
@@ -17,10 +17,19 @@ type Diag struct {
 type Document struct {
 	Lang   config.Lang
 	Style  config.Style
+	Input  config.InputFormat
+	Meta   []MetaField
 	Blocks []Block
 	Diags  []Diag
 }
 
+// MetaField is a front matter entry; Items holds the values of a list.
+type MetaField struct {
+	Key   string
+	Value string
+	Items []string
+}
+
 type Block interface{ isBlock() }
 
 type Paragraph struct{ In []Inline }
@@ -42,12 +51,35 @@ type Heading struct {
 
 func (Heading) isBlock() {}
 
+// TitleBlock is the title page: In is the title itself, Fields the author,
+// subtitle and the rest in source order.
 type TitleBlock struct {
-	In []Inline
+	In     []Inline
+	Fields []TitleField
 }
 
 func (TitleBlock) isBlock() {}
 
+type TitleFieldKind int
+
+const (
+	TitleAuthor TitleFieldKind = iota + 1
+	TitleSubtitle
+	TitleSeries
+	TitleTranslator
+	TitleYear
+)
+
+// TitleField is one part of the title page. Key keeps the label of a
+// `Перевод: …` line; fields read from bare lines have no key, and Before
+// tells the ones printed above the title.
+type TitleField struct {
+	Kind   TitleFieldKind
+	Key    string
+	Before bool
+	In     []Inline
+}
+
 type ContentsBlock struct {
 	In      []Inline
 	Entries []ContentsEntry
@@ -55,22 +87,105 @@ type ContentsBlock struct {
 
 func (ContentsBlock) isBlock() {}
 
+// ContentsEntry is a line of the contents; Page is the page reference of a
+// printed book, without the leaders.
 type ContentsEntry struct {
 	Level int
 	In    []Inline
+	Page  string
 }
 
+// MetaLineBlock is a `Key: value` line; Canonical is the vocabulary key
+// (author, title, year, ...) or empty for unknown keys.
 type MetaLineBlock struct {
-	Key string
-	In  []Inline
+	Key       string
+	Canonical string
+	In        []Inline
 }
 
 func (MetaLineBlock) isBlock() {}
 
+// SpeakerTurn is a line of an interview or transcript: `ИВАНОВ: текст`,
+// `Q: ...`.
+type SpeakerTurn struct {
+	Speaker string
+	In      []Inline
+}
+
+func (SpeakerTurn) isBlock() {}
+
+type ListKind int
+
+const (
+	ListUnordered ListKind = iota
+	ListOrdered
+)
+
+type ListBlock struct {
+	Kind  ListKind
+	Items []ListItem
+}
+
+func (ListBlock) isBlock() {}
+
+type ListItem struct {
+	Level  int
+	Kind   ListKind
+	Marker string
+	In     []Inline
+}
+
+// Epigraph is a quotation at the start of a book or chapter with its
+// `— Author, Work` attribution (without the dash). A verse quotation keeps
+// its line breaks in Lines and leaves In empty.
+type Epigraph struct {
+	In          []Inline
+	Lines       []VerseLine
+	Attribution []Inline
+}
+
+func (Epigraph) isBlock() {}
+
+// VerseBlock is a poem: stanzas of lines whose breaks are kept as is.
+type VerseBlock struct {
+	Stanzas []Stanza
+}
+
+func (VerseBlock) isBlock() {}
+
+type Stanza struct {
+	Lines []VerseLine
+}
+
+// VerseLine keeps the line indent relative to the least indented line of
+// its stanza.
+type VerseLine struct {
+	Indent int
+	In     []Inline
+}
+
+// FootnoteDef is the text of a note; Marker keeps the source label form
+// (`[1]`, `*`, `¹`). Scope counts the notes before it with the same Label,
+// from 1, so notes numbered anew in each chapter stay apart.
+type FootnoteDef struct {
+	Label  string
+	Marker string
+	Scope  int
+	In     []Inline
+}
+
+func (FootnoteDef) isBlock() {}
+
 type SceneBreak struct{ Marker string }
 
 func (SceneBreak) isBlock() {}
 
+// RawBlock holds source text that is printed byte-for-byte (Markdown syntax,
+// code blocks, tables, blank lines).
+type RawBlock struct{ Text string }
+
+func (RawBlock) isBlock() {}
+
 type Inline interface{ isInline() }
 
 type SpaceKind int
@@ -96,6 +211,26 @@ const (
 	QuoteSecondary
 )
 
+type VerbatimKind int
+
+const (
+	VerbatimURL VerbatimKind = iota
+	VerbatimEmail
+	VerbatimPath
+	VerbatimTag
+	VerbatimMention
+	VerbatimCode
+	VerbatimMarkup
+)
+
+type EmphasisKind int
+
+const (
+	EmphasisItalic EmphasisKind = iota
+	EmphasisStrong
+	EmphasisStrike
+)
+
 type Word struct{ S string }
 
 func (Word) isInline() {}
@@ -108,7 +243,12 @@ type Punct struct{ Ch rune }
 
 func (Punct) isInline() {}
 
-type Dash struct{ Kind DashKind }
+// Dash is a hyphen or dash; Typewriter marks a source "--" or "---", which
+// stays an em dash even between words.
+type Dash struct {
+	Kind       DashKind
+	Typewriter bool
+}
 
 func (Dash) isInline() {}
 
@@ -130,3 +270,29 @@ type ParenSpan struct {
 }
 
 func (ParenSpan) isInline() {}
+
+type Emphasis struct {
+	Kind   EmphasisKind
+	Marker string
+	In     []Inline
+}
+
+func (Emphasis) isInline() {}
+
+type Verbatim struct {
+	Kind VerbatimKind
+	S    string
+}
+
+func (Verbatim) isInline() {}
+
+// FootnoteRef points at the FootnoteDef with the same Label and Scope; N
+// numbers the references to that note from 1.
+type FootnoteRef struct {
+	Label  string
+	Marker string
+	Scope  int
+	N      int
+}
+
+func (FootnoteRef) isInline() {}
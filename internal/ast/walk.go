@@ -0,0 +1,60 @@
+package ast
+
+// MapInlines replaces every inline run of a block with fn of it, for the
+// passes of the parser and the rewriter that work on inlines alone.
+func MapInlines(blk Block, fn func([]Inline) []Inline) Block {
+	switch b := blk.(type) {
+	case TitleBlock:
+		b.In = fn(b.In)
+		for i := range b.Fields {
+			b.Fields[i].In = fn(b.Fields[i].In)
+		}
+		return b
+	case Paragraph:
+		b.In = fn(b.In)
+		return b
+	case Heading:
+		b.In = fn(b.In)
+		return b
+	case ContentsBlock:
+		b.In = fn(b.In)
+		for i := range b.Entries {
+			b.Entries[i].In = fn(b.Entries[i].In)
+		}
+		return b
+	case MetaLineBlock:
+		b.In = fn(b.In)
+		return b
+	case SpeakerTurn:
+		b.In = fn(b.In)
+		return b
+	case FootnoteDef:
+		b.In = fn(b.In)
+		return b
+	case Epigraph:
+		b.In = fn(b.In)
+		for i := range b.Lines {
+			b.Lines[i].In = fn(b.Lines[i].In)
+		}
+		b.Attribution = fn(b.Attribution)
+		return b
+	case DialogueBlock:
+		for i := range b.Turns {
+			b.Turns[i].In = fn(b.Turns[i].In)
+		}
+		return b
+	case ListBlock:
+		for i := range b.Items {
+			b.Items[i].In = fn(b.Items[i].In)
+		}
+		return b
+	case VerseBlock:
+		for i := range b.Stanzas {
+			for j := range b.Stanzas[i].Lines {
+				b.Stanzas[i].Lines[j].In = fn(b.Stanzas[i].Lines[j].In)
+			}
+		}
+		return b
+	}
+	return blk
+}
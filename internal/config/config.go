@@ -21,6 +21,44 @@ const (
 	InnerQuotesGuillemet InnerQuotes = "guillemets"
 )
 
+type InitialsSpace string
+
+const (
+	InitialsSpaceNBSP InitialsSpace = "nbsp"
+	InitialsSpaceThin InitialsSpace = "thin"
+)
+
+type InputFormat string
+
+const (
+	InputFormatPlain    InputFormat = "plain"
+	InputFormatMarkdown InputFormat = "markdown"
+)
+
+type DialogueStyle string
+
+const (
+	DialogueStyleKeep   DialogueStyle = "keep"
+	DialogueStyleDash   DialogueStyle = "dash"
+	DialogueStyleQuotes DialogueStyle = "quotes"
+)
+
+type MetaKeys string
+
+const (
+	MetaKeysAny    MetaKeys = "any"
+	MetaKeysKnown  MetaKeys = "known"
+	MetaKeysHeader MetaKeys = "header"
+)
+
+type TOCMode string
+
+const (
+	TOCKeep     TOCMode = "keep"
+	TOCGenerate TOCMode = "generate"
+	TOCCheck    TOCMode = "check"
+)
+
 type QuotePair struct {
 	Open  rune
 	Close rune
@@ -32,17 +70,29 @@ type Style struct {
 }
 
 type Config struct {
-	Lang        Lang
-	InnerQuotes InnerQuotes
-	UseNBSP     bool
-	Style       Style
+	Lang                Lang
+	InnerQuotes         InnerQuotes
+	UseNBSP             bool
+	Symbols             bool
+	InitialsSpace       InitialsSpace
+	InputFormat         InputFormat
+	DialogueStyle       DialogueStyle
+	SplitInlineDialogue bool
+	MetaKeys            MetaKeys
+	TOC                 TOCMode
+	Style               Style
 }
 
 func DefaultConfig() Config {
 	cfg := Config{
-		Lang:        LangRU,
-		InnerQuotes: InnerQuotesGerman,
-		UseNBSP:     false,
+		Lang:          LangRU,
+		InnerQuotes:   InnerQuotesGerman,
+		UseNBSP:       false,
+		InitialsSpace: InitialsSpaceNBSP,
+		InputFormat:   InputFormatPlain,
+		DialogueStyle: DialogueStyleKeep,
+		MetaKeys:      MetaKeysAny,
+		TOC:           TOCKeep,
 	}
 	cfg.Style = defaultStyleForLang(cfg.Lang)
 	return cfg
@@ -91,6 +141,66 @@ func validateInnerQuotes(inner InnerQuotes) error {
 	}
 }
 
+func ParseInitialsSpace(raw string) (InitialsSpace, error) {
+	s := InitialsSpace(strings.ToLower(strings.TrimSpace(raw)))
+	switch s {
+	case "":
+		return InitialsSpaceNBSP, nil
+	case InitialsSpaceNBSP, InitialsSpaceThin:
+		return s, nil
+	default:
+		return "", fmt.Errorf("unsupported -initials-space value %q (expected nbsp|thin)", raw)
+	}
+}
+
+func ParseInputFormat(raw string) (InputFormat, error) {
+	f := InputFormat(strings.ToLower(strings.TrimSpace(raw)))
+	switch f {
+	case "":
+		return InputFormatPlain, nil
+	case InputFormatPlain, InputFormatMarkdown:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported -input-format value %q (expected plain|markdown)", raw)
+	}
+}
+
+func ParseDialogueStyle(raw string) (DialogueStyle, error) {
+	s := DialogueStyle(strings.ToLower(strings.TrimSpace(raw)))
+	switch s {
+	case "":
+		return DialogueStyleKeep, nil
+	case DialogueStyleKeep, DialogueStyleDash, DialogueStyleQuotes:
+		return s, nil
+	default:
+		return "", fmt.Errorf("unsupported -dialogue-style value %q (expected dash|quotes|keep)", raw)
+	}
+}
+
+func ParseMetaKeys(raw string) (MetaKeys, error) {
+	m := MetaKeys(strings.ToLower(strings.TrimSpace(raw)))
+	switch m {
+	case "":
+		return MetaKeysAny, nil
+	case MetaKeysAny, MetaKeysKnown, MetaKeysHeader:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unsupported -meta-keys value %q (expected any|known|header)", raw)
+	}
+}
+
+func ParseTOCMode(raw string) (TOCMode, error) {
+	m := TOCMode(strings.ToLower(strings.TrimSpace(raw)))
+	switch m {
+	case "":
+		return TOCKeep, nil
+	case TOCKeep, TOCGenerate, TOCCheck:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unsupported -toc value %q (expected keep|generate|check)", raw)
+	}
+}
+
 func defaultStyleForLang(lang Lang) Style {
 	switch lang {
 	case LangEN:
@@ -10,10 +10,17 @@ import (
 type debugDocument struct {
 	Lang   string       `json:"lang"`
 	Style  debugStyle   `json:"style"`
+	Meta   []debugMeta  `json:"meta,omitempty"`
 	Blocks []debugBlock `json:"blocks"`
 	Diags  []debugDiag  `json:"diags,omitempty"`
 }
 
+type debugMeta struct {
+	Key   string   `json:"key"`
+	Value string   `json:"value,omitempty"`
+	Items []string `json:"items,omitempty"`
+}
+
 type debugStyle struct {
 	Outer debugPair `json:"outer"`
 	Inner debugPair `json:"inner"`
@@ -32,14 +39,43 @@ type debugDiag struct {
 }
 
 type debugBlock struct {
-	Kind    string         `json:"kind"`
-	Level   int            `json:"level,omitempty"`
-	Marker  string         `json:"marker,omitempty"`
-	Key     string         `json:"key,omitempty"`
-	In      []debugInline  `json:"in,omitempty"`
-	Entries []debugEntry   `json:"entries,omitempty"`
-	Turns   []debugTurn    `json:"turns,omitempty"`
-	Extra   map[string]any `json:"extra,omitempty"`
+	Kind      string         `json:"kind"`
+	Level     int            `json:"level,omitempty"`
+	Marker    string         `json:"marker,omitempty"`
+	Key       string         `json:"key,omitempty"`
+	Canonical string         `json:"canonical,omitempty"`
+	Text      string         `json:"text,omitempty"`
+	In        []debugInline  `json:"in,omitempty"`
+	Entries   []debugEntry   `json:"entries,omitempty"`
+	Turns     []debugTurn    `json:"turns,omitempty"`
+	Items     []debugItem    `json:"items,omitempty"`
+	Stanzas   []debugStanza  `json:"stanzas,omitempty"`
+	Fields    []debugField   `json:"fields,omitempty"`
+	Cite      []debugInline  `json:"attribution,omitempty"`
+	Extra     map[string]any `json:"extra,omitempty"`
+}
+
+type debugStanza struct {
+	Lines []debugVerseLine `json:"lines"`
+}
+
+type debugVerseLine struct {
+	Indent int           `json:"indent,omitempty"`
+	In     []debugInline `json:"in"`
+}
+
+type debugItem struct {
+	Level  int           `json:"level"`
+	Kind   string        `json:"kind"`
+	Marker string        `json:"marker"`
+	In     []debugInline `json:"in"`
+}
+
+type debugField struct {
+	Kind   string        `json:"kind"`
+	Key    string        `json:"key,omitempty"`
+	Before bool          `json:"before,omitempty"`
+	In     []debugInline `json:"in"`
 }
 
 type debugTurn struct {
@@ -49,6 +85,7 @@ type debugTurn struct {
 type debugEntry struct {
 	Level int           `json:"level"`
 	In    []debugInline `json:"in"`
+	Page  string        `json:"page,omitempty"`
 }
 
 type debugInline struct {
@@ -57,6 +94,9 @@ type debugInline struct {
 	Ch    string        `json:"ch,omitempty"`
 	Space string        `json:"space,omitempty"`
 	Dash  string        `json:"dash,omitempty"`
+	Verb  string        `json:"verbatim,omitempty"`
+	Emph  string        `json:"emphasis,omitempty"`
+	Mark  string        `json:"marker,omitempty"`
 	Level string        `json:"level,omitempty"`
 	Open  string        `json:"open,omitempty"`
 	Close string        `json:"close,omitempty"`
@@ -74,6 +114,9 @@ func WriteAST(w io.Writer, doc ast.Document) error {
 		Diags:  make([]debugDiag, 0, len(doc.Diags)),
 	}
 
+	for _, f := range doc.Meta {
+		payload.Meta = append(payload.Meta, debugMeta{Key: f.Key, Value: f.Value, Items: f.Items})
+	}
 	for _, d := range doc.Diags {
 		payload.Diags = append(payload.Diags, debugDiag{
 			Line:    d.Pos.Line,
@@ -101,9 +144,14 @@ func WriteAST(w io.Writer, doc ast.Document) error {
 func mapBlock(blk ast.Block) debugBlock {
 	switch b := blk.(type) {
 	case ast.TitleBlock:
+		fields := make([]debugField, 0, len(b.Fields))
+		for _, f := range b.Fields {
+			fields = append(fields, debugField{Kind: titleFieldKindName(f.Kind), Key: f.Key, Before: f.Before, In: mapInlines(f.In)})
+		}
 		return debugBlock{
-			Kind: "TitleBlock",
-			In:   mapInlines(b.In),
+			Kind:   "TitleBlock",
+			In:     mapInlines(b.In),
+			Fields: fields,
 		}
 	case ast.Paragraph:
 		return debugBlock{
@@ -131,6 +179,7 @@ func mapBlock(blk ast.Block) debugBlock {
 			entries = append(entries, debugEntry{
 				Level: entry.Level,
 				In:    mapInlines(entry.In),
+				Page:  entry.Page,
 			})
 		}
 		return debugBlock{
@@ -140,8 +189,15 @@ func mapBlock(blk ast.Block) debugBlock {
 		}
 	case ast.MetaLineBlock:
 		return debugBlock{
-			Kind: "MetaLineBlock",
-			Key:  b.Key,
+			Kind:      "MetaLineBlock",
+			Key:       b.Key,
+			Canonical: b.Canonical,
+			In:        mapInlines(b.In),
+		}
+	case ast.SpeakerTurn:
+		return debugBlock{
+			Kind: "SpeakerTurn",
+			Key:  b.Speaker,
 			In:   mapInlines(b.In),
 		}
 	case ast.SceneBreak:
@@ -149,6 +205,61 @@ func mapBlock(blk ast.Block) debugBlock {
 			Kind:   "SceneBreak",
 			Marker: b.Marker,
 		}
+	case ast.ListBlock:
+		items := make([]debugItem, 0, len(b.Items))
+		for _, item := range b.Items {
+			items = append(items, debugItem{
+				Level:  item.Level,
+				Kind:   listKindString(item.Kind),
+				Marker: item.Marker,
+				In:     mapInlines(item.In),
+			})
+		}
+		return debugBlock{
+			Kind:   "ListBlock",
+			Marker: listKindString(b.Kind),
+			Items:  items,
+		}
+	case ast.Epigraph:
+		var stanzas []debugStanza
+		if len(b.Lines) > 0 {
+			lines := make([]debugVerseLine, 0, len(b.Lines))
+			for _, line := range b.Lines {
+				lines = append(lines, debugVerseLine{Indent: line.Indent, In: mapInlines(line.In)})
+			}
+			stanzas = []debugStanza{{Lines: lines}}
+		}
+		return debugBlock{
+			Kind:    "Epigraph",
+			In:      mapInlines(b.In),
+			Stanzas: stanzas,
+			Cite:    mapInlines(b.Attribution),
+		}
+	case ast.VerseBlock:
+		stanzas := make([]debugStanza, 0, len(b.Stanzas))
+		for _, stanza := range b.Stanzas {
+			lines := make([]debugVerseLine, 0, len(stanza.Lines))
+			for _, line := range stanza.Lines {
+				lines = append(lines, debugVerseLine{Indent: line.Indent, In: mapInlines(line.In)})
+			}
+			stanzas = append(stanzas, debugStanza{Lines: lines})
+		}
+		return debugBlock{
+			Kind:    "VerseBlock",
+			Stanzas: stanzas,
+		}
+	case ast.FootnoteDef:
+		return debugBlock{
+			Kind:   "FootnoteDef",
+			Marker: b.Marker,
+			Key:    b.Label,
+			In:     mapInlines(b.In),
+		}
+	case ast.RawBlock:
+		return debugBlock{
+			Kind: "RawBlock",
+			Text: b.Text,
+		}
 	default:
 		return debugBlock{
 			Kind: "UnknownBlock",
@@ -170,6 +281,17 @@ func mapInlines(in []ast.Inline) []debugInline {
 			out = append(out, debugInline{Kind: "Dash", Dash: dashKindString(it.Kind)})
 		case ast.Ellipsis:
 			out = append(out, debugInline{Kind: "Ellipsis"})
+		case ast.Emphasis:
+			out = append(out, debugInline{
+				Kind: "Emphasis",
+				Emph: emphasisKindString(it.Kind),
+				Mark: it.Marker,
+				In:   mapInlines(it.In),
+			})
+		case ast.Verbatim:
+			out = append(out, debugInline{Kind: "Verbatim", Text: it.S, Verb: verbatimKindString(it.Kind)})
+		case ast.FootnoteRef:
+			out = append(out, debugInline{Kind: "FootnoteRef", Text: it.Label, Mark: it.Marker})
 		case ast.QuoteSpan:
 			out = append(out, debugInline{
 				Kind:  "QuoteSpan",
@@ -212,6 +334,43 @@ func dashKindString(k ast.DashKind) string {
 	}
 }
 
+func listKindString(k ast.ListKind) string {
+	if k == ast.ListOrdered {
+		return "Ordered"
+	}
+	return "Unordered"
+}
+
+func emphasisKindString(k ast.EmphasisKind) string {
+	switch k {
+	case ast.EmphasisStrong:
+		return "Strong"
+	case ast.EmphasisStrike:
+		return "Strike"
+	default:
+		return "Italic"
+	}
+}
+
+func verbatimKindString(k ast.VerbatimKind) string {
+	switch k {
+	case ast.VerbatimEmail:
+		return "Email"
+	case ast.VerbatimPath:
+		return "Path"
+	case ast.VerbatimTag:
+		return "Tag"
+	case ast.VerbatimMention:
+		return "Mention"
+	case ast.VerbatimCode:
+		return "Code"
+	case ast.VerbatimMarkup:
+		return "Markup"
+	default:
+		return "URL"
+	}
+}
+
 func quoteLevelString(level ast.QuoteLevel) string {
 	switch level {
 	case ast.QuoteSecondary:
@@ -220,3 +379,20 @@ func quoteLevelString(level ast.QuoteLevel) string {
 		return "Primary"
 	}
 }
+
+func titleFieldKindName(kind ast.TitleFieldKind) string {
+	switch kind {
+	case ast.TitleAuthor:
+		return "Author"
+	case ast.TitleSubtitle:
+		return "Subtitle"
+	case ast.TitleSeries:
+		return "Series"
+	case ast.TitleTranslator:
+		return "Translator"
+	case ast.TitleYear:
+		return "Year"
+	default:
+		return "Unknown"
+	}
+}
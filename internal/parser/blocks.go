@@ -14,8 +14,19 @@ var (
 	headingRe         = regexp.MustCompile(`^(#{1,6})\s+(\S.*)$`)
 	bookHeadingRe     = regexp.MustCompile(`(?i)^(глава|chapter|часть|частина|part|раздел|section|книга|book|том|volume|розділ)\s+(.+)$`)
 	contentsHeadingRe = regexp.MustCompile(`(?i)^(содержание|оглавление|contents|зміст)$`)
+	contentsLeaderRe  = regexp.MustCompile(`^(.*?\S)[ \t]*(?:(?:[.·][ \t]*){4,}|(?:…[ \t]*){2,})(\d{1,4}|[IVXLCDMivxlcdm]{1,7})$`)
+	contentsTabPageRe = regexp.MustCompile(`^(.*?\S)\t+(\d{1,4}|[IVXLCDMivxlcdm]{1,7})$`)
 	xSceneBreakRe     = regexp.MustCompile(`^[xXхХ](?:\s+[xXхХ]){2,}$`)
-	metaLineRe        = regexp.MustCompile(`^(\p{L}[\p{L}\p{N}_-]{1,30}):\s+(\S.*)$`)
+	metaLineRe        = regexp.MustCompile(`^(\p{L}[\p{L}\p{N}_-]{1,30}(?:\(\p{L}{1,3}\))?):\s+(\S.*)$`)
+	speakerLetterRe   = regexp.MustCompile(`^(\p{Lu}):\s+(\S.*)$`)
+	metaPhraseLineRe  = regexp.MustCompile(`^(\p{L}[\p{L} ]{1,30}):\s+(\S.*)$`)
+)
+
+// A line at least this long and this close to the longest line of its
+// candidate was cut by hard wrapping.
+const (
+	minWrappedLineLen     = 50
+	minWrappedLinePercent = 80
 )
 
 type candidate struct {
@@ -29,21 +40,37 @@ type sourceLine struct {
 }
 
 func Parse(input string, cfg config.Config) ast.Document {
+	if cfg.InputFormat == config.InputFormatMarkdown {
+		return parseMarkdown(input, cfg)
+	}
 	input = strings.ReplaceAll(input, "\r\n", "\n")
 	input = strings.ReplaceAll(input, "\r", "\n")
 	rawLines := strings.Split(input, "\n")
-	lines := prepareSourceLines(rawLines)
+	meta, metaDiags, source := splitFrontMatter(prepareSourceLines(rawLines))
+	lines, pageDiags := stripPageArtifacts(source)
 	candidates := splitCandidates(lines)
 
 	doc := ast.Document{
 		Lang:   cfg.Lang,
 		Style:  cfg.Style,
+		Meta:   meta,
 		Blocks: make([]ast.Block, 0, len(lines)),
-		Diags:  nil,
+		Diags:  append(metaDiags, pageDiags...),
 	}
 
+	afterColon := false
+	blockLines := make([]int, 0, len(candidates))
+	var entryLines []int
+	contentsSeen := false
+	prevLine := 0
 	for i := 0; i < len(candidates); i++ {
 		c := candidates[i]
+		for len(blockLines) < len(doc.Blocks) {
+			blockLines = append(blockLines, prevLine)
+		}
+		prevLine = c.lineNums[0]
+		prevColon := afterColon
+		afterColon = strings.HasSuffix(strings.TrimSpace(c.lines[len(c.lines)-1]), ":")
 
 		if i == 0 {
 			if title, diags, consumed, ok := parseLeadingTitleCandidates(candidates); ok {
@@ -54,14 +81,32 @@ func Parse(input string, cfg config.Config) ast.Document {
 			}
 		}
 
+		if len(doc.Blocks) > 0 && opensEpigraph(doc.Blocks[len(doc.Blocks)-1]) {
+			if epigraph, diags, consumed, ok := parseEpigraphCandidates(candidates[i:]); ok {
+				doc.Blocks = append(doc.Blocks, epigraph)
+				doc.Diags = append(doc.Diags, diags...)
+				i += consumed - 1
+				continue
+			}
+		}
+
 		if contents, diags, ok := parseContentsCandidate(c); ok {
+			first := !contentsSeen
+			contentsSeen = true
 			j := i + 1
 			for j < len(candidates) {
-				entry, entryDiags, entryOK := parseContentsEntryCandidate(candidates[j])
+				entries, entryDiags, entryOK := parseContentsEntryCandidate(candidates[j])
 				if !entryOK {
 					break
 				}
-				contents.Entries = append(contents.Entries, entry)
+				// The first entry coming round again is the first chapter.
+				if j > i+1 && sameContentsEntry(entries[0], contents.Entries[0]) {
+					break
+				}
+				contents.Entries = append(contents.Entries, entries...)
+				if first {
+					entryLines = append(entryLines, candidates[j].lineNums...)
+				}
 				diags = append(diags, entryDiags...)
 				j++
 			}
@@ -72,11 +117,58 @@ func Parse(input string, cfg config.Config) ast.Document {
 			continue
 		}
 
+		if blocks, diags, ok := parseFootnoteCandidate(c); ok {
+			for j, line := range c.lines {
+				if _, _, _, def := parseFootnoteDefLine(line); def {
+					blockLines = append(blockLines, c.lineNums[j])
+				}
+			}
+			doc.Blocks = append(doc.Blocks, blocks...)
+			doc.Diags = append(doc.Diags, diags...)
+			continue
+		}
+
+		last := len(doc.Blocks) - 1
+		prevVerse, afterVerse := ast.VerseBlock{}, false
+		if last >= 0 {
+			prevVerse, afterVerse = doc.Blocks[last].(ast.VerseBlock)
+		}
+		// A title page set in short lines is no poem.
+		titlePage := i == 0 && looksLikeTitlePage(c.lines)
+		if stanza, diags, ok := parseVerseStanza(c, afterVerse); ok && !titlePage {
+			if afterVerse {
+				prevVerse.Stanzas = append(prevVerse.Stanzas, stanza)
+				doc.Blocks[last] = prevVerse
+			} else {
+				doc.Blocks = append(doc.Blocks, ast.VerseBlock{Stanzas: []ast.Stanza{stanza}})
+			}
+			doc.Diags = append(doc.Diags, diags...)
+			continue
+		}
+
+		if blocks, diags, ok := parseListCandidate(c, prevColon); ok {
+			doc.Blocks = append(doc.Blocks, blocks...)
+			doc.Diags = append(doc.Diags, diags...)
+			continue
+		}
+
 		if blocks, diags, ok := parseCandidate(c); ok {
 			doc.Blocks = append(doc.Blocks, blocks...)
 			doc.Diags = append(doc.Diags, diags...)
 		}
 	}
+	for len(blockLines) < len(doc.Blocks) {
+		blockLines = append(blockLines, prevLine)
+	}
+	scopeFootnotes(doc.Blocks)
+	doc.Diags = append(doc.Diags, checkFootnotes(doc.Blocks, blockLines)...)
+	if cfg.TOC == config.TOCCheck {
+		doc.Diags = append(doc.Diags, checkContents(doc.Blocks, blockLines, entryLines)...)
+	}
+	doc.Blocks = absorbTitleMeta(restrictMetaLines(detectSpeakerTurns(doc.Blocks, blockLines), cfg.MetaKeys))
+	if cfg.TOC == config.TOCGenerate {
+		doc.Blocks = generateContents(doc.Blocks, cfg.Lang)
+	}
 
 	return doc
 }
@@ -102,7 +194,7 @@ func parseSingleCandidateTitle(first candidate, tail []candidate) (ast.TitleBloc
 	switch len(first.lines) {
 	case 1:
 		return parseOneLineTitleCandidate(first, tail)
-	case 2:
+	case 2, 3:
 		return parseTwoLineTitleCandidate(first, tail)
 	default:
 		return ast.TitleBlock{}, nil, false
@@ -124,15 +216,22 @@ func parseOneLineTitleCandidate(first candidate, tail []candidate) (ast.TitleBlo
 	return ast.TitleBlock{In: in}, diags, true
 }
 
+// parseTwoLineTitleCandidate reads a title page of two lines, or three with
+// a subtitle, set as one candidate.
 func parseTwoLineTitleCandidate(first candidate, tail []candidate) (ast.TitleBlock, []ast.Diag, bool) {
-	if !looksLikeTitlePart(first.lines[0]) || !looksLikeTitlePart(first.lines[1]) {
+	for _, line := range first.lines {
+		if !looksLikeTitlePart(line) {
+			return ast.TitleBlock{}, nil, false
+		}
+	}
+	title, diags := buildTitleBlock(first.lines, first.lineNums)
+	if len(first.lines) == 3 && len(title.Fields) != 2 {
 		return ast.TitleBlock{}, nil, false
 	}
-	if !isFrontMatterPrefix(tail) {
+	if !isFrontMatterPrefix(tail) && !standsAsTitle(title, first.lines) {
 		return ast.TitleBlock{}, nil, false
 	}
-	in, diags := parseInlineLines(first.lines, first.lineNums, true)
-	return ast.TitleBlock{In: in}, diags, true
+	return title, diags, true
 }
 
 func parseTwoCandidateTitle(candidates []candidate) (ast.TitleBlock, []ast.Diag, bool) {
@@ -155,14 +254,13 @@ func parseTwoCandidateTitle(candidates []candidate) (ast.TitleBlock, []ast.Diag,
 		return ast.TitleBlock{}, nil, false
 	}
 
-	if !isFrontMatterPrefix(candidates[2:]) {
-		return ast.TitleBlock{}, nil, false
-	}
-
 	lines := []string{first.lines[0], second.lines[0]}
 	lineNums := []int{first.lineNums[0], second.lineNums[0]}
-	in, diags := parseInlineLines(lines, lineNums, true)
-	return ast.TitleBlock{In: in}, diags, true
+	title, diags := buildTitleBlock(lines, lineNums)
+	if !isFrontMatterPrefix(candidates[2:]) && !standsAsTitle(title, lines) {
+		return ast.TitleBlock{}, nil, false
+	}
+	return title, diags, true
 }
 
 type frontMatterKind int
@@ -193,7 +291,7 @@ func classifyFrontMatterCandidate(c candidate) frontMatterKind {
 	if _, ok := parseContentsLine(line); ok {
 		return frontMatterContents
 	}
-	if _, _, ok := parseContentsEntryLine(line); ok {
+	if _, _, _, ok := parseContentsEntryLine(line, false); ok {
 		return frontMatterContentsEntry
 	}
 	return frontMatterUnknown
@@ -254,6 +352,23 @@ func looksLikeSingleLineTitle(line string) bool {
 	return true
 }
 
+// looksLikeTitlePage reports a leading candidate set like a title page: a
+// few short lines with no end punctuation, at least one in capitals, as in
+// "ЛЕВ ТОЛСТОЙ / ВОЙНА И МИР / Роман в четырёх томах".
+func looksLikeTitlePage(lines []string) bool {
+	if len(lines) > 4 {
+		return false
+	}
+	caps := false
+	for _, line := range lines {
+		if !looksLikeTitlePart(line) || endsWithClausePunct(line) {
+			return false
+		}
+		caps = caps || isCapsLine(line)
+	}
+	return caps
+}
+
 func looksLikeTitlePart(line string) bool {
 	t := normalizeStructureLine(line)
 	if t == "" {
@@ -303,7 +418,7 @@ func splitTrailingSceneBreak(line string) (string, string, bool) {
 	}
 
 	i := len(r) - 1
-	for i >= 0 && (unicode.IsSpace(r[i]) || isSceneBreakRune(r[i])) {
+	for i >= 0 && (unicode.IsSpace(r[i]) || isSceneBreakRune(r[i]) || isOrnamentRune(r[i])) {
 		i--
 	}
 
@@ -378,6 +493,9 @@ func isStandaloneStructuralLine(line string) bool {
 	if _, ok := parseContentsLine(line); ok {
 		return true
 	}
+	if contentsLeaderRe.MatchString(strings.TrimSpace(line)) {
+		return true
+	}
 	_, _, ok := parseHeading(line)
 	return ok
 }
@@ -388,13 +506,14 @@ func parseCandidate(c candidate) ([]ast.Block, []ast.Diag, bool) {
 	}
 
 	if len(c.lines) == 1 && isSceneBreak(c.lines[0]) {
-		return []ast.Block{ast.SceneBreak{Marker: "***"}}, nil, true
+		return []ast.Block{ast.SceneBreak{Marker: normalizeStructureLine(c.lines[0])}}, nil, true
 	}
 
 	if len(c.lines) == 1 {
 		if key, value, ok := parseMetaLine(c.lines[0]); ok {
 			in, diags := parseInlineLines([]string{value}, c.lineNums, false)
-			return []ast.Block{ast.MetaLineBlock{Key: key, In: in}}, diags, true
+			canonical, _ := canonicalMetaKey(key)
+			return []ast.Block{ast.MetaLineBlock{Key: key, Canonical: canonical, In: in}}, diags, true
 		}
 		if level, body, ok := parseHeading(c.lines[0]); ok {
 			in, diags := parseInlineLines([]string{body}, c.lineNums, false)
@@ -425,14 +544,27 @@ func parseCandidate(c candidate) ([]ast.Block, []ast.Diag, bool) {
 	return blocks, allDiags, true
 }
 
+// parseDialogueTurns starts a turn at every dialogue line; the lines that
+// follow it until the next dash are its wrapped continuation.
 func parseDialogueTurns(lines []string, lineNums []int) ([]ast.DialogueTurn, []ast.Diag) {
 	turns := make([]ast.DialogueTurn, 0, len(lines))
 	var allDiags []ast.Diag
-	for i, line := range lines {
-		trimmed, col := trimLeftWithCol(line)
-		in, diags := parseInlineLinesWithCols([]string{trimmed}, []int{lineNums[i]}, []int{col}, false)
+	for i := 0; i < len(lines); {
+		j := i + 1
+		for j < len(lines) && !isDialogueLine(lines[j]) {
+			j++
+		}
+		texts := make([]string, 0, j-i)
+		cols := make([]int, 0, j-i)
+		for _, line := range lines[i:j] {
+			trimmed, col := trimLeftWithCol(line)
+			texts = append(texts, trimmed)
+			cols = append(cols, col)
+		}
+		in, diags := parseInlineLinesWithCols(texts, lineNums[i:j], cols, true)
 		turns = append(turns, ast.DialogueTurn{In: in})
 		allDiags = append(allDiags, diags...)
+		i = j
 	}
 	return turns, allDiags
 }
@@ -446,6 +578,10 @@ func parseInlineLines(lines []string, lineNums []int, joinWithSpace bool) ([]ast
 }
 
 func parseInlineLinesWithCols(lines []string, lineNums []int, cols []int, joinWithSpace bool) ([]ast.Inline, []ast.Diag) {
+	var hyphenDiags []ast.Diag
+	if joinWithSpace {
+		lines, lineNums, cols, hyphenDiags = dehyphenateLines(lines, lineNums, cols)
+	}
 	var toks []token
 	for i, line := range lines {
 		toks = append(toks, tokenizeInline(line, lineNums[i], cols[i])...)
@@ -453,21 +589,50 @@ func parseInlineLinesWithCols(lines []string, lineNums []int, cols []int, joinWi
 			toks = append(toks, token{kind: tokenSpace, text: " ", pos: ast.Pos{Line: lineNums[i], Col: utf8.RuneCountInString(lines[i]) + 1}})
 		}
 	}
-	return buildSpans(toks)
+	in, diags := buildSpans(toks)
+	return in, append(hyphenDiags, diags...)
 }
 
+// isDialogueCandidate reports lines that are all dialogue turns, counting
+// wrapped continuation lines as part of their turn.
 func isDialogueCandidate(lines []string) bool {
-	if len(lines) == 0 {
+	if len(lines) == 0 || !isDialogueLine(lines[0]) {
 		return false
 	}
-	for _, line := range lines {
-		if !isDialogueLine(line) {
+	width := wrapWidth(lines)
+	for i := 1; i < len(lines); i++ {
+		if !isDialogueLine(lines[i]) && !continuesDialogueTurn(lines[i-1], lines[i], width) {
 			return false
 		}
 	}
 	return true
 }
 
+// continuesDialogueTurn reports that cur is the wrapped tail of the turn
+// ending with prev: it starts like a continuation, or prev filled the wrap
+// width without finishing a sentence.
+func continuesDialogueTurn(prev, cur string, width int) bool {
+	if isDialogueLine(cur) || strings.TrimSpace(cur) == "" {
+		return false
+	}
+	if startsLikelyContinuation(cur) {
+		return true
+	}
+	p := strings.TrimSpace(prev)
+	n := lineRuneLen(p)
+	return !endsLikeSentence(p) && n >= minWrappedLineLen && n*100 >= width*minWrappedLinePercent
+}
+
+// wrapWidth is the longest line of a candidate, the best guess at the width
+// hard-wrapped text was cut to.
+func wrapWidth(lines []string) int {
+	width := 0
+	for _, line := range lines {
+		width = max(width, lineRuneLen(strings.TrimSpace(line)))
+	}
+	return width
+}
+
 func isDialogueLine(s string) bool {
 	t := strings.TrimLeft(s, " \t")
 	r := []rune(t)
@@ -488,13 +653,17 @@ func isSceneBreak(line string) bool {
 	}
 
 	compact := strings.ReplaceAll(t, " ", "")
+	if strings.TrimFunc(compact, isOrnamentRune) == "" {
+		// A single ornament such as ⁂ or § is enough.
+		return true
+	}
 	if utf8.RuneCountInString(compact) < 3 {
 		return false
 	}
 
 	onlyXLike := true
 	for _, r := range compact {
-		if !isSceneBreakRune(r) {
+		if !isSceneBreakRune(r) && !isOrnamentRune(r) {
 			return false
 		}
 		if !isXLikeRune(r) {
@@ -516,6 +685,15 @@ func isSceneBreakRune(r rune) bool {
 	}
 }
 
+func isOrnamentRune(r rune) bool {
+	switch r {
+	case '⁂', '§', '❦', '❧', '✻', '✽', '❖', '◆', '◇', '♦', '※', '⸙':
+		return true
+	default:
+		return false
+	}
+}
+
 func isXLikeRune(r rune) bool {
 	switch r {
 	case 'x', 'X', 'х', 'Х':
@@ -579,16 +757,35 @@ func parseContentsCandidate(c candidate) (ast.ContentsBlock, []ast.Diag, bool) {
 	return ast.ContentsBlock{In: in}, diags, true
 }
 
-func parseContentsEntryCandidate(c candidate) (ast.ContentsEntry, []ast.Diag, bool) {
+// parseContentsEntryCandidate reads the entries under a contents heading.
+// Lines with a tab before the page number don't stand alone, so a
+// candidate may hold several of them.
+func parseContentsEntryCandidate(c candidate) ([]ast.ContentsEntry, []ast.Diag, bool) {
 	if len(c.lines) != 1 {
-		return ast.ContentsEntry{}, nil, false
+		for _, line := range c.lines {
+			if !contentsTabPageRe.MatchString(strings.TrimSpace(line)) {
+				return nil, nil, false
+			}
+		}
 	}
-	level, body, ok := parseContentsEntryLine(c.lines[0])
-	if !ok {
-		return ast.ContentsEntry{}, nil, false
+	entries := make([]ast.ContentsEntry, 0, len(c.lines))
+	var diags []ast.Diag
+	for k, line := range c.lines {
+		level, body, page, ok := parseContentsEntryLine(line, true)
+		if !ok {
+			return nil, nil, false
+		}
+		in, inDiags := parseInlineLines([]string{body}, c.lineNums[k:k+1], false)
+		entries = append(entries, ast.ContentsEntry{Level: level, In: in, Page: page})
+		diags = append(diags, inDiags...)
 	}
-	in, diags := parseInlineLines([]string{body}, c.lineNums, false)
-	return ast.ContentsEntry{Level: level, In: in}, diags, true
+	return entries, diags, true
+}
+
+// sameContentsEntry compares entries by their words, so the heading
+// "ГЛАВА 1. НАЧАЛО" matches the entry "Глава 1. Начало ..... 5".
+func sameContentsEntry(a, b ast.ContentsEntry) bool {
+	return strings.EqualFold(contentsText(a.In), contentsText(b.In))
 }
 
 func parseContentsLine(line string) (string, bool) {
@@ -610,27 +807,40 @@ func parseContentsLine(line string) (string, bool) {
 	return "", false
 }
 
-func parseContentsEntryLine(line string) (int, string, bool) {
+// parseContentsEntryLine reads a chapter line of the contents. A page
+// reference after dot leaders (`Глава 1. Начало ..... 5`) is cut off into
+// page. Inside a contents block a tab also marks the page and a page makes
+// any title an entry; elsewhere only chapter lines are entries.
+func parseContentsEntryLine(line string, inContents bool) (int, string, string, bool) {
+	page := ""
+	if m := contentsLeaderRe.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+		line, page = m[1], m[2]
+	} else if m := contentsTabPageRe.FindStringSubmatch(strings.TrimSpace(line)); m != nil && inContents {
+		line, page = m[1], m[2]
+	}
 	t := normalizeStructureLine(line)
 	if t == "" {
-		return 0, "", false
+		return 0, "", "", false
 	}
 
 	t = trimHeadingDecorations(t)
 	if t == "" {
-		return 0, "", false
+		return 0, "", "", false
 	}
 
 	m := bookHeadingRe.FindStringSubmatch(t)
 	if m == nil {
-		return 0, "", false
+		if page != "" && inContents {
+			return 2, t, page, true
+		}
+		return 0, "", "", false
 	}
 	kw := strings.ToLower(m[1])
 	body := strings.TrimSpace(m[2])
 	if body == "" {
-		return 0, "", false
+		return 0, "", "", false
 	}
-	return headingLevelForKeyword(kw), t, true
+	return headingLevelForKeyword(kw), t, page, true
 }
 
 func parseMetaLine(line string) (string, string, bool) {
@@ -640,7 +850,17 @@ func parseMetaLine(line string) (string, string, bool) {
 	}
 	m := metaLineRe.FindStringSubmatch(t)
 	if m == nil {
-		return "", "", false
+		m = speakerLetterRe.FindStringSubmatch(t)
+	}
+	if m == nil {
+		// Keys of several words are only the known ones: "Год издания".
+		m = metaPhraseLineRe.FindStringSubmatch(t)
+		if m == nil {
+			return "", "", false
+		}
+		if _, ok := canonicalMetaKey(m[1]); !ok {
+			return "", "", false
+		}
 	}
 	key := strings.TrimSpace(m[1])
 	value := strings.TrimSpace(m[2])
@@ -708,13 +928,19 @@ func splitParagraphByIndent(lines []string, lineNums []int) []candidate {
 
 	out := make([]candidate, 0, 1)
 	start := 0
+	width := wrapWidth(lines)
+	segDialogue := isDialogueLine(lines[0])
 	for i := 1; i < len(lines); i++ {
-		if shouldSplitBetweenLines(lines[i-1], lines[i]) {
+		if segDialogue && continuesDialogueTurn(lines[i-1], lines[i], width) {
+			continue
+		}
+		if shouldSplitBetweenLines(lines[i-1], lines[i], segDialogue) {
 			out = append(out, candidate{
 				lines:    lines[start:i],
 				lineNums: lineNums[start:i],
 			})
 			start = i
+			segDialogue = isDialogueLine(lines[i])
 		}
 	}
 
@@ -725,10 +951,8 @@ func splitParagraphByIndent(lines []string, lineNums []int) []candidate {
 	return out
 }
 
-func shouldSplitBetweenLines(prev, cur string) bool {
-	prevDialogue := isDialogueLine(prev)
-	curDialogue := isDialogueLine(cur)
-	if prevDialogue != curDialogue {
+func shouldSplitBetweenLines(prev, cur string, segDialogue bool) bool {
+	if isDialogueLine(cur) != segDialogue {
 		return true
 	}
 	return hasLeadingIndent(cur) && !hasLeadingIndent(prev)
@@ -759,10 +983,11 @@ func shouldSplitLongIndentedLines(lines []string) bool {
 
 func splitLongIndentedParagraphs(lines []string, lineNums []int) []candidate {
 	out := make([]candidate, 0, len(lines))
+	width := wrapWidth(lines)
 	for i := 0; i < len(lines); {
 		if isDialogueLine(lines[i]) {
 			j := i + 1
-			for j < len(lines) && isDialogueLine(lines[j]) {
+			for j < len(lines) && (isDialogueLine(lines[j]) || continuesDialogueTurn(lines[j-1], lines[j], width)) {
 				j++
 			}
 			out = append(out, candidate{
@@ -39,6 +39,9 @@ func TestIsSceneBreak(t *testing.T) {
 		{"-----", true},
 		{"x x x", true},
 		{"х х х", true},
+		{"⁂", true},
+		{"§ § §", true},
+		{"          * * *", true},
 		{"**", false},
 		{"x x", false},
 		{"abc", false},
@@ -113,8 +116,8 @@ func TestParseMixedLayoutBlocks(t *testing.T) {
 	if _, ok := doc.Blocks[3].(ast.DialogueBlock); !ok {
 		t.Fatalf("expected block 3 DialogueBlock, got %T", doc.Blocks[3])
 	}
-	if _, ok := doc.Blocks[4].(ast.SceneBreak); !ok {
-		t.Fatalf("expected block 4 SceneBreak, got %T", doc.Blocks[4])
+	if sb, ok := doc.Blocks[4].(ast.SceneBreak); !ok || sb.Marker != "* * *" {
+		t.Fatalf("expected block 4 SceneBreak with source marker, got %#v", doc.Blocks[4])
 	}
 	if _, ok := doc.Blocks[5].(ast.Paragraph); !ok {
 		t.Fatalf("expected block 5 Paragraph, got %T", doc.Blocks[5])
@@ -580,3 +583,985 @@ func TestParseUkrainianBookLikeLayout(t *testing.T) {
 		t.Fatalf("expected block 4 Paragraph, got %T", doc.Blocks[4])
 	}
 }
+
+func TestParseListBlocks(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	cases := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"hyphen list after colon", "Нужно купить:\n- хлеб;\n- молоко.", []string{"Paragraph", "List"}},
+		{"dialogue after colon", "Он сказал:\n- Привет!\n- Пока!", []string{"Paragraph", "Dialogue"}},
+		{"dialogue after colon with periods", "Он сказал:\n- Привет.\n- Пока.", []string{"Paragraph", "Dialogue"}},
+		{"question and answer after colon", "Он спросил:\n- Ты придёшь завтра?\n- Да.", []string{"Paragraph", "Dialogue"}},
+		{"dialogue", "- Привет, - сказал он.\n- Здравствуй.", []string{"Dialogue"}},
+		{"short hyphen items", "- яблоки\n- груши", []string{"List"}},
+		{"short capitalized items", "- Яблоки\n- Груши\n- Сливы", []string{"List"}},
+		{"short replies", "- Да\n- Нет", []string{"Dialogue"}},
+		{"bullets", "• один\n• два", []string{"List"}},
+		{"numbered", "1. Первый шаг\n2) Второй шаг", []string{"List"}},
+		{"letters", "а) первое\nб) второе", []string{"List"}},
+		{"number in text", "1990. Год начался.", []string{"Paragraph"}},
+		{"numbers out of order", "1. Один\n3. Три", []string{"Paragraph"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc := Parse(tc.input, cfg)
+			got := make([]string, 0, len(doc.Blocks))
+			for _, blk := range doc.Blocks {
+				switch blk.(type) {
+				case ast.Paragraph:
+					got = append(got, "Paragraph")
+				case ast.ListBlock:
+					got = append(got, "List")
+				case ast.DialogueBlock:
+					got = append(got, "Dialogue")
+				default:
+					got = append(got, "Other")
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseNestedListLevels(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	doc := Parse("1. Первый\n2. Второй\n   а) подпункт\n   с продолжением\n   б) ещё", cfg)
+	if len(doc.Blocks) != 1 {
+		t.Fatalf("expected one block, got %d", len(doc.Blocks))
+	}
+	list, ok := doc.Blocks[0].(ast.ListBlock)
+	if !ok {
+		t.Fatalf("expected ListBlock, got %T", doc.Blocks[0])
+	}
+	levels := make([]int, 0, len(list.Items))
+	for _, item := range list.Items {
+		levels = append(levels, item.Level)
+	}
+	if len(levels) != 4 || levels[0] != 1 || levels[1] != 1 || levels[2] != 2 || levels[3] != 2 {
+		t.Fatalf("unexpected levels %v", levels)
+	}
+	if list.Kind != ast.ListOrdered || list.Items[2].Marker != "а)" {
+		t.Fatalf("unexpected list %#v", list)
+	}
+	if len(list.Items[2].In) != 5 {
+		t.Fatalf("expected continuation joined into item, got %#v", list.Items[2].In)
+	}
+}
+
+func TestParseFootnoteDefinitions(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	input := "" +
+		"Он ушёл[1], а она осталась*.\n" +
+		"Потом ещё[4].\n" +
+		"\n" +
+		"* * *\n" +
+		"\n" +
+		"[1] Первая сноска,\n" +
+		"длинная.\n" +
+		"* Звёздочка.\n" +
+		"[3] Лишняя.\n"
+
+	doc := Parse(input, cfg)
+	if len(doc.Blocks) != 5 {
+		t.Fatalf("expected 5 blocks, got %d: %#v", len(doc.Blocks), doc.Blocks)
+	}
+	if _, ok := doc.Blocks[1].(ast.SceneBreak); !ok {
+		t.Fatalf("expected block 1 SceneBreak, got %T", doc.Blocks[1])
+	}
+	first, ok := doc.Blocks[2].(ast.FootnoteDef)
+	if !ok || first.Label != "1" || first.Marker != "[1]" {
+		t.Fatalf("expected footnote [1], got %#v", doc.Blocks[2])
+	}
+	if len(first.In) != 7 {
+		t.Fatalf("expected continuation joined into footnote, got %#v", first.In)
+	}
+	if star, ok := doc.Blocks[3].(ast.FootnoteDef); !ok || star.Label != "*" {
+		t.Fatalf("expected footnote *, got %#v", doc.Blocks[3])
+	}
+
+	if len(doc.Diags) != 2 {
+		t.Fatalf("expected 2 diags, got %#v", doc.Diags)
+	}
+	if d := doc.Diags[0]; d.Code != "FOOTNOTE_UNDEFINED" || d.Pos.Line != 1 {
+		t.Fatalf("expected undefined [4] at line 1, got %#v", d)
+	}
+	if d := doc.Diags[1]; d.Code != "FOOTNOTE_UNUSED" || d.Pos.Line != 9 {
+		t.Fatalf("expected unused [3] at line 9, got %#v", d)
+	}
+}
+
+func TestParseFootnotesNumberedPerChapter(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	input := "" +
+		"Слово[1] и снова[1].\n" +
+		"\n" +
+		"[1] Первая.\n" +
+		"\n" +
+		"Другое¹ слово.\n" +
+		"\n" +
+		"¹ Вторая.\n"
+
+	doc := Parse(input, cfg)
+	if len(doc.Blocks) != 4 {
+		t.Fatalf("expected 4 blocks, got %d: %#v", len(doc.Blocks), doc.Blocks)
+	}
+	refs := inlineFootnoteRefs(doc.Blocks[0].(ast.Paragraph).In)
+	if len(refs) != 2 || refs[0].Scope != 1 || refs[0].N != 1 || refs[1].Scope != 1 || refs[1].N != 2 {
+		t.Fatalf("expected two refs to the first note, got %#v", refs)
+	}
+	if def, ok := doc.Blocks[1].(ast.FootnoteDef); !ok || def.Scope != 1 {
+		t.Fatalf("expected first note in scope 1, got %#v", doc.Blocks[1])
+	}
+	refs = inlineFootnoteRefs(doc.Blocks[2].(ast.Paragraph).In)
+	if len(refs) != 1 || refs[0].Scope != 2 || refs[0].N != 1 {
+		t.Fatalf("expected ref to the second note, got %#v", refs)
+	}
+	if def, ok := doc.Blocks[3].(ast.FootnoteDef); !ok || def.Label != "1" || def.Scope != 2 {
+		t.Fatalf("expected second note in scope 2, got %#v", doc.Blocks[3])
+	}
+
+	if len(doc.Diags) != 0 {
+		t.Fatalf("expected no diags for notes numbered per chapter, got %#v", doc.Diags)
+	}
+
+	doc = Parse("Слово[1].\n\n[1] Первая.\n\n[1] Опять первая.\n", cfg)
+	if len(doc.Diags) != 1 {
+		t.Fatalf("expected 1 diag, got %#v", doc.Diags)
+	}
+	if d := doc.Diags[0]; d.Code != "FOOTNOTE_DUPLICATE" || d.Pos.Line != 5 {
+		t.Fatalf("expected duplicate note at line 5, got %#v", d)
+	}
+}
+
+func TestParseStarBulletsAreNotFootnotes(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	doc := Parse("Список:\n* один\n* два\n", cfg)
+	for _, blk := range doc.Blocks {
+		if _, ok := blk.(ast.FootnoteDef); ok {
+			t.Fatalf("unexpected footnote in %#v", doc.Blocks)
+		}
+	}
+}
+
+func TestParseVerseStanzas(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	input := "" +
+		"Мой дядя самых честных правил,\n" +
+		"Когда не в шутку занемог,\n" +
+		"Он уважать себя заставил\n" +
+		"И лучше выдумать не мог.\n" +
+		"\n" +
+		"Его пример другим наука;\n" +
+		"    Но, боже мой, какая скука\n" +
+		"\n" +
+		"Обычный абзац после стихов.\n"
+
+	doc := Parse(input, cfg)
+	if len(doc.Blocks) != 2 {
+		t.Fatalf("expected 2 blocks, got %d: %#v", len(doc.Blocks), doc.Blocks)
+	}
+	verse, ok := doc.Blocks[0].(ast.VerseBlock)
+	if !ok {
+		t.Fatalf("expected block 0 VerseBlock, got %T", doc.Blocks[0])
+	}
+	if len(verse.Stanzas) != 2 || len(verse.Stanzas[0].Lines) != 4 || len(verse.Stanzas[1].Lines) != 2 {
+		t.Fatalf("unexpected stanzas %#v", verse.Stanzas)
+	}
+	if verse.Stanzas[1].Lines[1].Indent != 4 {
+		t.Fatalf("expected indent 4, got %d", verse.Stanzas[1].Lines[1].Indent)
+	}
+	if _, ok := doc.Blocks[1].(ast.Paragraph); !ok {
+		t.Fatalf("expected block 1 Paragraph, got %T", doc.Blocks[1])
+	}
+}
+
+func TestParseShortProseIsNotVerse(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	for _, input := range []string{
+		"Он ушёл.\nОна осталась.\nВсе молчали.\n",
+		"Было холодно и ветрено,\nно мы шли вперёд, потому что\nвыбора не оставалось.\n",
+		"Короткая строка,\nИ ещё одна.\n",
+	} {
+		doc := Parse(input, cfg)
+		for _, blk := range doc.Blocks {
+			if _, ok := blk.(ast.VerseBlock); ok {
+				t.Fatalf("unexpected verse in %q", input)
+			}
+		}
+	}
+}
+
+func TestParseEpigraphAfterHeading(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	input := "" +
+		"Глава 1\n" +
+		"\n" +
+		"    Всё смешалось в доме Облонских.\n" +
+		"— Л. Толстой, «Анна Каренина»\n" +
+		"\n" +
+		"«Быть или не быть».\n" +
+		"\n" +
+		"— Шекспир\n" +
+		"\n" +
+		"Он вошёл.\n"
+
+	doc := Parse(input, cfg)
+	if len(doc.Blocks) != 4 {
+		t.Fatalf("expected 4 blocks, got %d: %#v", len(doc.Blocks), doc.Blocks)
+	}
+	first, ok := doc.Blocks[1].(ast.Epigraph)
+	if !ok {
+		t.Fatalf("expected block 1 Epigraph, got %T", doc.Blocks[1])
+	}
+	if w, ok := first.Attribution[0].(ast.Word); !ok || w.S != "Л" {
+		t.Fatalf("expected attribution without dash, got %#v", first.Attribution)
+	}
+	if _, ok := doc.Blocks[2].(ast.Epigraph); !ok {
+		t.Fatalf("expected block 2 Epigraph, got %T", doc.Blocks[2])
+	}
+	if _, ok := doc.Blocks[3].(ast.Paragraph); !ok {
+		t.Fatalf("expected block 3 Paragraph, got %T", doc.Blocks[3])
+	}
+}
+
+func TestParseTitlePageIsNotVerse(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	doc := Parse("ЛЕВ ТОЛСТОЙ\nВОЙНА И МИР\nРоман в четырёх томах\n\nТекст начинается здесь.\n", cfg)
+	for _, blk := range doc.Blocks {
+		if _, ok := blk.(ast.VerseBlock); ok {
+			t.Fatalf("expected no verse in a title page, got %#v", doc.Blocks)
+		}
+	}
+}
+
+func TestParseVerseEpigraphKeepsLines(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	input := "" +
+		"Глава 1\n" +
+		"\n" +
+		"Мой дядя самых честных правил,\n" +
+		"Когда не в шутку занемог,\n" +
+		"Он уважать себя заставил\n" +
+		"— А. С. Пушкин\n" +
+		"\n" +
+		"Он вошёл.\n"
+
+	doc := Parse(input, cfg)
+	epigraph, ok := doc.Blocks[1].(ast.Epigraph)
+	if !ok {
+		t.Fatalf("expected block 1 Epigraph, got %T", doc.Blocks[1])
+	}
+	if len(epigraph.Lines) != 3 || len(epigraph.In) != 0 {
+		t.Fatalf("expected 3 verse lines, got %#v", epigraph)
+	}
+}
+
+func TestParseDialogueAfterHeadingIsNotEpigraph(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	for _, input := range []string{
+		"Глава 1\n\nОн вошёл.\n— Привет!\n",
+		"Глава 1\n\nОн вошёл.\n— Пора идти.\n",
+		"Он вошёл.\n— Иван Петров\n",
+		"Глава 1\n\nОн долго шёл по дороге и думал о своём.\n— Маша, привет\n",
+	} {
+		doc := Parse(input, cfg)
+		for _, blk := range doc.Blocks {
+			if _, ok := blk.(ast.Epigraph); ok {
+				t.Fatalf("unexpected epigraph in %q", input)
+			}
+		}
+	}
+}
+
+func TestDehyphenateLines(t *testing.T) {
+	cases := []struct {
+		name  string
+		lines []string
+		want  []string
+		diag  bool
+	}{
+		{name: "broken word", lines: []string{"Это пере-", "нос слова"}, want: []string{"Это перенос", "слова"}},
+		{name: "english", lines: []string{"every-", "thing."}, want: []string{"everything."}},
+		{name: "particle", lines: []string{"кто-", "то пришёл"}, want: []string{"кто-то", "пришёл"}},
+		{name: "prefix", lines: []string{"кое-", "как"}, want: []string{"кое-как"}},
+		{name: "adverb", lines: []string{"по-", "русски"}, want: []string{"по-русски"}},
+		{name: "capitalized", lines: []string{"Нью-", "Йорк"}, want: []string{"Нью-Йорк"}},
+		{name: "range", lines: []string{"1990-", "2000 годы"}, want: []string{"1990-2000", "годы"}},
+		{name: "spaced dash", lines: []string{"он -", "и она"}, want: []string{"он -", "и она"}},
+		{name: "ambiguous", lines: []string{"по-", "могу"}, want: []string{"помогу"}, diag: true},
+		{name: "iz compound", lines: []string{"из-", "за двери"}, want: []string{"из-за", "двери"}},
+		{name: "iz word", lines: []string{"из-", "вестный"}, want: []string{"известный"}},
+		{name: "ambiguous prefix", lines: []string{"экс-", "порт"}, want: []string{"экс-порт"}, diag: true},
+		{name: "english compound", lines: []string{"well-", "known author"}, want: []string{"well-known", "author"}},
+		{name: "ordinal", lines: []string{"во-", "первых, в-", "третьих"}, want: []string{"во-первых,", "в-третьих"}},
+		{name: "hyphenated head", lines: []string{"mother-in-", "law"}, want: []string{"mother-in-law"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			nums := make([]int, len(tc.lines))
+			cols := make([]int, len(tc.lines))
+			for i := range tc.lines {
+				nums[i], cols[i] = i+1, 1
+			}
+			got, _, _, diags := dehyphenateLines(tc.lines, nums, cols)
+			if strings.Join(got, "|") != strings.Join(tc.want, "|") {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+			if tc.diag != (len(diags) == 1 && diags[0].Code == "DEHYPHENATE_AMBIGUOUS") {
+				t.Fatalf("unexpected diags %#v", diags)
+			}
+		})
+	}
+}
+
+func TestParseStripsPageArtifacts(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	input := "" +
+		"Первый абзац.\n\nВторое предложение было\n\n- 10 -\n\nПОВЕСТЬ\n\nразорвано страницей.\n\n" +
+		"Третий абзац.\n\nЧетвёртое предложение было\n\n- 11 -\n\nПОВЕСТЬ\n\nразорвано страницей.\n\n" +
+		"Пятый абзац.\n\nШестое предложение было\n\n- 12 -\n\nПОВЕСТЬ\n\nразорвано страницей.\n"
+
+	doc := Parse(input, cfg)
+	if len(doc.Blocks) != 6 {
+		t.Fatalf("expected 6 blocks, got %d: %#v", len(doc.Blocks), doc.Blocks)
+	}
+	joined, ok := doc.Blocks[1].(ast.Paragraph)
+	if !ok || len(joined.In) != 10 {
+		t.Fatalf("expected split sentence rejoined, got %#v", doc.Blocks[1])
+	}
+	if len(doc.Diags) != 6 {
+		t.Fatalf("expected 6 diags, got %#v", doc.Diags)
+	}
+	for _, d := range doc.Diags {
+		if d.Code != "PAGE_ARTIFACT_REMOVED" {
+			t.Fatalf("unexpected diag %#v", d)
+		}
+	}
+}
+
+func TestParseKeepsNumberedSections(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	input := "1\n\nКороткая часть.\n\n" +
+		"2\n\n" + strings.Repeat("Длинная часть текста.\n\n", 12) +
+		"3\n\nЕщё часть.\n"
+	doc := Parse(input, cfg)
+	if len(doc.Diags) != 0 {
+		t.Fatalf("unexpected diags %#v", doc.Diags)
+	}
+
+	section := "Короткая часть текста.\n\nЕщё одна строка.\n\n"
+	input = "1\n\n" + section + "2\n\n" + section + "3\n\n" + section + "4\n\n" + section
+	doc = Parse(input, cfg)
+	if len(doc.Diags) != 0 {
+		t.Fatalf("evenly spaced sections: unexpected diags %#v", doc.Diags)
+	}
+	if p, ok := doc.Blocks[0].(ast.Paragraph); !ok || len(p.In) != 1 {
+		t.Fatalf("expected section number kept as paragraph, got %#v", doc.Blocks[0])
+	}
+
+	section = strings.Repeat("Короткий абзац.\n\n", 14)
+	input = "1\n\n" + section + "2\n\n" + section + "3\n\n" + section + "4\n\n" + section
+	doc = Parse(input, cfg)
+	if len(doc.Diags) != 0 {
+		t.Fatalf("far apart sections: unexpected diags %#v", doc.Diags)
+	}
+}
+
+func TestParseStripsBarePageNumbers(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	page := strings.Repeat("Строка текста.\n", 30)
+	input := page + "Первая фраза была\n\n12\n\nразорвана.\n" +
+		page + "Вторая фраза была\n\n13\n\nразорвана.\n" +
+		page + "Третья фраза была\n\n14\n\nразорвана.\n"
+	doc := Parse(input, cfg)
+	if len(doc.Diags) != 3 {
+		t.Fatalf("expected 3 diags, got %#v", doc.Diags)
+	}
+	for _, d := range doc.Diags {
+		if d.Code != "PAGE_ARTIFACT_REMOVED" {
+			t.Fatalf("unexpected diag %#v", d)
+		}
+	}
+}
+
+func TestParseWrappedDialogueTurns(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	input := "" +
+		"— Привет, — сказал он, подходя к окну и глядя на заснеженную\n" +
+		"улицу. — Давно не виделись.\n" +
+		"— Давно, — ответила она, не отрываясь от книги, которую держала\n" +
+		"в руках уже второй час подряд и никак не могла дочитать до\n" +
+		"конца.\n" +
+		"Она вздохнула и закрыла книгу.\n" +
+		"— Ну что ж.\n"
+
+	doc := Parse(input, cfg)
+	if len(doc.Blocks) != 3 {
+		t.Fatalf("expected 3 blocks, got %d: %#v", len(doc.Blocks), doc.Blocks)
+	}
+	dialogue, ok := doc.Blocks[0].(ast.DialogueBlock)
+	if !ok || len(dialogue.Turns) != 2 {
+		t.Fatalf("expected 2 wrapped turns, got %#v", doc.Blocks[0])
+	}
+	last := dialogue.Turns[1].In
+	if w, ok := last[len(last)-2].(ast.Word); !ok || w.S != "конца" {
+		t.Fatalf("expected continuation joined into turn, got %#v", last)
+	}
+	if _, ok := doc.Blocks[1].(ast.Paragraph); !ok {
+		t.Fatalf("expected block 1 Paragraph, got %T", doc.Blocks[1])
+	}
+	if _, ok := doc.Blocks[2].(ast.DialogueBlock); !ok {
+		t.Fatalf("expected block 2 DialogueBlock, got %T", doc.Blocks[2])
+	}
+}
+
+func TestParseYAMLFrontMatter(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	input := "" +
+		"---\n" +
+		"title: \"Ночь: начало\"\n" +
+		"author: Иван Петров\n" +
+		"tags:\n" +
+		"  - проза\n" +
+		"  - ночь\n" +
+		"---\n" +
+		"\n" +
+		"Текст первой главы.\n"
+
+	doc := Parse(input, cfg)
+	if len(doc.Meta) != 3 {
+		t.Fatalf("expected 3 meta fields, got %#v", doc.Meta)
+	}
+	if doc.Meta[0].Value != "Ночь: начало" || doc.Meta[1].Key != "author" {
+		t.Fatalf("unexpected scalar fields %#v", doc.Meta)
+	}
+	if strings.Join(doc.Meta[2].Items, "|") != "проза|ночь" {
+		t.Fatalf("unexpected list field %#v", doc.Meta[2])
+	}
+	if len(doc.Blocks) != 1 {
+		t.Fatalf("expected front matter removed from blocks, got %#v", doc.Blocks)
+	}
+	if len(doc.Diags) != 0 {
+		t.Fatalf("unexpected diags %#v", doc.Diags)
+	}
+
+	doc = Parse("---\ntitle: Ночь\n{broken}\n---\n\nТекст.\n", cfg)
+	if len(doc.Meta) != 0 {
+		t.Fatalf("expected invalid front matter ignored, got %#v", doc.Meta)
+	}
+	if len(doc.Blocks) < 3 {
+		t.Fatalf("expected invalid front matter kept as text, got %#v", doc.Blocks)
+	}
+	if len(doc.Diags) != 1 || doc.Diags[0].Code != "FRONT_MATTER_INVALID" || doc.Diags[0].Pos.Line != 3 {
+		t.Fatalf("expected FRONT_MATTER_INVALID on line 3, got %#v", doc.Diags)
+	}
+}
+
+func TestParseSceneBreaksAroundProseAreNotFrontMatter(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	for _, input := range []string{
+		"---\nОн ушёл.\n---\nУтро.\n",
+		"---\nВчера он сказал: приходи завтра.\nИ ушёл, не попрощавшись.\n---\nУтро.\n",
+	} {
+		doc := Parse(input, cfg)
+		if len(doc.Meta) != 0 || len(doc.Diags) != 0 {
+			t.Fatalf("unexpected meta %#v, diags %#v", doc.Meta, doc.Diags)
+		}
+		if _, ok := doc.Blocks[0].(ast.SceneBreak); !ok {
+			t.Fatalf("expected leading SceneBreak, got %#v", doc.Blocks)
+		}
+		if _, ok := doc.Blocks[2].(ast.SceneBreak); !ok || len(doc.Blocks) != 4 {
+			t.Fatalf("expected both scene breaks kept around the text, got %#v", doc.Blocks)
+		}
+	}
+}
+
+func TestParseStructuredTitlePage(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	input := "" +
+		"Лев Толстой\n" +
+		"Война и мир\n" +
+		"\n" +
+		"Перевод: И. Иванов\n" +
+		"OCR: Bot\n" +
+		"\n" +
+		"Глава 1\n" +
+		"\n" +
+		"Текст.\n"
+
+	doc := Parse(input, cfg)
+	title, ok := doc.Blocks[0].(ast.TitleBlock)
+	if !ok {
+		t.Fatalf("expected TitleBlock, got %T", doc.Blocks[0])
+	}
+	if w, ok := title.In[0].(ast.Word); !ok || w.S != "Война" {
+		t.Fatalf("unexpected title %#v", title.In)
+	}
+	if len(title.Fields) != 2 {
+		t.Fatalf("expected author and translator fields, got %#v", title.Fields)
+	}
+	if f := title.Fields[0]; f.Kind != ast.TitleAuthor || !f.Before || f.Key != "" {
+		t.Fatalf("unexpected author field %#v", f)
+	}
+	if f := title.Fields[1]; f.Kind != ast.TitleTranslator || f.Key != "Перевод" {
+		t.Fatalf("unexpected translator field %#v", f)
+	}
+	if meta, ok := doc.Blocks[1].(ast.MetaLineBlock); !ok || meta.Key != "OCR" {
+		t.Fatalf("expected unrelated meta line kept, got %#v", doc.Blocks[1])
+	}
+}
+
+func TestParseTitleWithSubtitle(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	doc := Parse("Война и мир\nроман в четырёх томах\n\nГлава 1\n\nТекст.\n", cfg)
+	title, ok := doc.Blocks[0].(ast.TitleBlock)
+	if !ok || len(title.Fields) != 1 || title.Fields[0].Kind != ast.TitleSubtitle || title.Fields[0].Before {
+		t.Fatalf("expected title with subtitle, got %#v", doc.Blocks[0])
+	}
+}
+
+func TestParseTitlePageWithoutFrontMatter(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	doc := Parse("ЛЕВ ТОЛСТОЙ\nВОЙНА И МИР\nРоман в четырёх томах\n\nТекст начинается здесь.\n", cfg)
+	title, ok := doc.Blocks[0].(ast.TitleBlock)
+	if !ok || len(title.Fields) != 2 {
+		t.Fatalf("expected title with author and subtitle, got %#v", doc.Blocks[0])
+	}
+	if w, ok := title.In[0].(ast.Word); !ok || w.S != "ВОЙНА" {
+		t.Fatalf("unexpected title %#v", title.In)
+	}
+	if f := title.Fields[0]; f.Kind != ast.TitleAuthor || !f.Before {
+		t.Fatalf("unexpected author field %#v", f)
+	}
+	if f := title.Fields[1]; f.Kind != ast.TitleSubtitle || f.Before {
+		t.Fatalf("unexpected subtitle field %#v", f)
+	}
+
+	doc = Parse("Лев Толстой\nВойна и мир\n\nТекст начинается здесь.\n", cfg)
+	if title, ok := doc.Blocks[0].(ast.TitleBlock); !ok || len(title.Fields) != 1 {
+		t.Fatalf("expected title with author, got %#v", doc.Blocks[0])
+	}
+
+	doc = Parse("Он пришёл домой\nи лёг спать.\n\nУтром всё.\n", cfg)
+	if _, ok := doc.Blocks[0].(ast.Paragraph); !ok {
+		t.Fatalf("expected narration kept as paragraph, got %#v", doc.Blocks[0])
+	}
+}
+
+func TestParseTitlePageInCapitals(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	cases := []struct {
+		input  string
+		title  string
+		before bool
+	}{
+		{"Лев Толстой\nВОЙНА И МИР", "ВОЙНА", true},
+		{"ВОЙНА И МИР\nЛЕВ ТОЛСТОЙ", "ВОЙНА", false},
+		{"CHARLES DICKENS\nGREAT EXPECTATIONS", "GREAT", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			doc := Parse(tc.input+"\n\nГлава 1\n\nТекст.\n", cfg)
+			title, ok := doc.Blocks[0].(ast.TitleBlock)
+			if !ok || len(title.Fields) != 1 {
+				t.Fatalf("expected title with one field, got %#v", doc.Blocks[0])
+			}
+			if w, ok := title.In[0].(ast.Word); !ok || w.S != tc.title {
+				t.Fatalf("unexpected title %#v", title.In)
+			}
+			if f := title.Fields[0]; f.Kind != ast.TitleAuthor || f.Before != tc.before {
+				t.Fatalf("unexpected author field %#v", f)
+			}
+		})
+	}
+}
+
+func TestParseTitleMetaKeepsSourceOrder(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	input := "Война и мир\n\nАвтор: Лев Толстой\nOCR: Bot\nПеревод: И. Иванов\n\nТекст.\n"
+	doc := Parse(input, cfg)
+	title, ok := doc.Blocks[0].(ast.TitleBlock)
+	if !ok || len(title.Fields) != 1 || title.Fields[0].Kind != ast.TitleAuthor {
+		t.Fatalf("expected title with author, got %#v", doc.Blocks[0])
+	}
+	for i, key := range []string{"OCR", "Перевод"} {
+		if meta, ok := doc.Blocks[i+1].(ast.MetaLineBlock); !ok || meta.Key != key {
+			t.Fatalf("expected meta line %q at block %d, got %#v", key, i+1, doc.Blocks[i+1])
+		}
+	}
+}
+
+func TestParseCanonicalMetaKeys(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	input := "" +
+		"АВТОР(Ы): Стругацкие\n" +
+		"Год издания: 1972\n" +
+		"Настроение: хорошее\n" +
+		"\n" +
+		"Первый абзац текста.\n" +
+		"\n" +
+		"ISBN: 978-5-17-000000-0\n"
+
+	doc := Parse(input, cfg)
+	if len(doc.Blocks) != 5 {
+		t.Fatalf("expected 5 blocks, got %d", len(doc.Blocks))
+	}
+	for i, canonical := range map[int]string{0: "author", 1: "year", 2: "", 4: "isbn"} {
+		meta, ok := doc.Blocks[i].(ast.MetaLineBlock)
+		if !ok {
+			t.Fatalf("expected block %d MetaLineBlock, got %T", i, doc.Blocks[i])
+		}
+		if meta.Canonical != canonical {
+			t.Fatalf("block %d: expected canonical %q, got %q", i, canonical, meta.Canonical)
+		}
+	}
+
+	cfg.MetaKeys = config.MetaKeysKnown
+	doc = Parse(input, cfg)
+	if _, ok := doc.Blocks[2].(ast.Paragraph); !ok {
+		t.Fatalf("known: expected unknown key to become Paragraph, got %T", doc.Blocks[2])
+	}
+	if _, ok := doc.Blocks[4].(ast.MetaLineBlock); !ok {
+		t.Fatalf("known: expected ISBN to stay MetaLineBlock, got %T", doc.Blocks[4])
+	}
+
+	cfg.MetaKeys = config.MetaKeysHeader
+	doc = Parse(input, cfg)
+	if _, ok := doc.Blocks[2].(ast.MetaLineBlock); !ok {
+		t.Fatalf("header: expected header key to stay MetaLineBlock, got %T", doc.Blocks[2])
+	}
+	if _, ok := doc.Blocks[4].(ast.Paragraph); !ok {
+		t.Fatalf("header: expected body meta line to become Paragraph, got %T", doc.Blocks[4])
+	}
+}
+
+func TestParseSpeakerTurns(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	input := "" +
+		"OCR: Василий Новиков\n" +
+		"\n" +
+		"Q: Где вы были?\n" +
+		"A: Дома.\n" +
+		"Q: Один?\n" +
+		"A: Да.\n"
+
+	doc := Parse(input, cfg)
+	if len(doc.Blocks) != 5 {
+		t.Fatalf("expected 5 blocks, got %d", len(doc.Blocks))
+	}
+	if _, ok := doc.Blocks[0].(ast.MetaLineBlock); !ok {
+		t.Fatalf("expected block 0 MetaLineBlock, got %T", doc.Blocks[0])
+	}
+	for i, speaker := range []string{"Q", "A", "Q", "A"} {
+		turn, ok := doc.Blocks[i+1].(ast.SpeakerTurn)
+		if !ok {
+			t.Fatalf("expected block %d SpeakerTurn, got %T", i+1, doc.Blocks[i+1])
+		}
+		if turn.Speaker != speaker {
+			t.Fatalf("block %d: expected speaker %q, got %q", i+1, speaker, turn.Speaker)
+		}
+	}
+
+	doc = Parse("Q: Где вы были?\n\nТишина.\n", cfg)
+	if _, ok := doc.Blocks[0].(ast.Paragraph); !ok {
+		t.Fatalf("expected a lone one-letter key to stay Paragraph, got %T", doc.Blocks[0])
+	}
+}
+
+func TestParseSpeakerTurnsSpanLines(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	input := "" +
+		"ИВАНОВ: Я был дома весь вечер,\n" +
+		"никуда не выходил.\n" +
+		"СЛЕДОВАТЕЛЬ: Кто это подтвердит?\n" +
+		"ИВАНОВ: Жена.\n" +
+		"\n" +
+		"Она была рядом.\n" +
+		"\n" +
+		"СЛЕДОВАТЕЛЬ: Хорошо.\n" +
+		"\n" +
+		"Конец допроса.\n"
+
+	doc := Parse(input, cfg)
+	if len(doc.Blocks) != 6 {
+		t.Fatalf("expected 6 blocks, got %d: %#v", len(doc.Blocks), doc.Blocks)
+	}
+	turn, ok := doc.Blocks[0].(ast.SpeakerTurn)
+	if !ok || turn.Speaker != "ИВАНОВ" || len(turn.In) != 17 {
+		t.Fatalf("expected block 0 to hold the whole turn, got %#v", doc.Blocks[0])
+	}
+	for _, i := range []int{3, 5} {
+		if _, ok := doc.Blocks[i].(ast.Paragraph); !ok {
+			t.Fatalf("expected narration kept as block %d, got %T", i, doc.Blocks[i])
+		}
+	}
+}
+
+func TestParseSpeakerTurnsWithOneRepeatingKey(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	doc := Parse("ИВАНОВ: Я был дома.\nПЕТРОВ: Неправда.\nИВАНОВ: Правда.\n", cfg)
+	if len(doc.Blocks) != 3 {
+		t.Fatalf("expected 3 blocks, got %d: %#v", len(doc.Blocks), doc.Blocks)
+	}
+	for i, blk := range doc.Blocks {
+		if _, ok := blk.(ast.SpeakerTurn); !ok {
+			t.Fatalf("expected block %d SpeakerTurn, got %T", i, blk)
+		}
+	}
+}
+
+func TestParseContentsCheck(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	cfg.TOC = config.TOCCheck
+
+	input := "" +
+		"СОДЕРЖАНИЕ\n" +
+		"Глава I Пролог\n" +
+		"Глава II Развязка\n" +
+		"Глава III Финал\n" +
+		"\n" +
+		"Глава I Пролог\n" +
+		"\n" +
+		"Текст.\n" +
+		"\n" +
+		"Глава II. Развязка событий\n" +
+		"\n" +
+		"Текст.\n" +
+		"\n" +
+		"Глава IV Эпилог\n"
+
+	doc := Parse(input, cfg)
+	contents, ok := doc.Blocks[0].(ast.ContentsBlock)
+	if !ok || len(contents.Entries) != 3 {
+		t.Fatalf("expected contents with 3 entries, got %#v", doc.Blocks[0])
+	}
+	got := map[string]int{}
+	for _, d := range doc.Diags {
+		got[d.Code] = d.Pos.Line
+	}
+	want := map[string]int{"TOC_MISMATCH": 10, "TOC_EXTRA": 4, "TOC_MISSING": 14}
+	for code, line := range want {
+		if got[code] != line {
+			t.Fatalf("expected %s at line %d, got diags %#v", code, line, doc.Diags)
+		}
+	}
+	if len(doc.Diags) != len(want) {
+		t.Fatalf("expected %d diags, got %#v", len(want), doc.Diags)
+	}
+}
+
+func TestParseContentsStopsAtCapsChapter(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	input := "" +
+		"СОДЕРЖАНИЕ\n" +
+		"\n" +
+		"Глава 1. Начало ........ 5\n" +
+		"\n" +
+		"Глава 2. Конец ........ 9\n" +
+		"\n" +
+		"ГЛАВА 1. НАЧАЛО\n" +
+		"\n" +
+		"Текст.\n"
+
+	doc := Parse(input, cfg)
+	contents, ok := doc.Blocks[0].(ast.ContentsBlock)
+	if !ok || len(contents.Entries) != 2 {
+		t.Fatalf("expected contents with 2 entries, got %#v", doc.Blocks[0])
+	}
+	if _, ok := doc.Blocks[1].(ast.Heading); !ok {
+		t.Fatalf("expected the chapter heading after the contents, got %#v", doc.Blocks[1])
+	}
+}
+
+func TestParseContentsGenerate(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	cfg.TOC = config.TOCGenerate
+
+	doc := Parse("Глава 1. Начало\n\nТекст.\n\nГлава 2. Конец\n\nТекст.\n", cfg)
+	contents, ok := doc.Blocks[0].(ast.ContentsBlock)
+	if !ok {
+		t.Fatalf("expected block 0 ContentsBlock, got %T", doc.Blocks[0])
+	}
+	if len(contents.Entries) != 2 || contents.Entries[1].Level != 2 {
+		t.Fatalf("unexpected entries %#v", contents.Entries)
+	}
+	if w, ok := contents.In[0].(ast.Word); !ok || w.S != "Содержание" {
+		t.Fatalf("unexpected contents title %#v", contents.In)
+	}
+}
+
+func TestParseContentsPageNumbers(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	input := "" +
+		"СОДЕРЖАНИЕ\n" +
+		"Глава 1. Начало ........ 5\n" +
+		"Глава 2. Середина . . . . . 17\n" +
+		"Послесловие …… XII\n"
+
+	doc := Parse(input, cfg)
+	contents, ok := doc.Blocks[0].(ast.ContentsBlock)
+	if !ok || len(doc.Blocks) != 1 {
+		t.Fatalf("expected a single ContentsBlock, got %#v", doc.Blocks)
+	}
+	want := []struct{ text, page string }{
+		{"Глава 1 Начало", "5"},
+		{"Глава 2 Середина", "17"},
+		{"Послесловие", "XII"},
+	}
+	if len(contents.Entries) != len(want) {
+		t.Fatalf("expected %d entries, got %d", len(want), len(contents.Entries))
+	}
+	for i, w := range want {
+		entry := contents.Entries[i]
+		if entry.Page != w.page {
+			t.Fatalf("entry %d: expected page %q, got %q", i, w.page, entry.Page)
+		}
+		if got := contentsText(entry.In); got != w.text {
+			t.Fatalf("entry %d: unexpected text %q", i, got)
+		}
+	}
+}
+
+func TestParsePageNumbersOutsideContents(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	doc := Parse("Продажи за неделю были такими:\nЯблоки\t10\nГруши\t20\n", cfg)
+	if len(doc.Blocks) != 1 {
+		t.Fatalf("expected one paragraph, got %#v", doc.Blocks)
+	}
+	if _, ok := doc.Blocks[0].(ast.Paragraph); !ok {
+		t.Fatalf("expected Paragraph, got %T", doc.Blocks[0])
+	}
+
+	doc = Parse("СОДЕРЖАНИЕ\nПролог\t3\nЭпилог\t9\n", cfg)
+	contents, ok := doc.Blocks[0].(ast.ContentsBlock)
+	if !ok || len(contents.Entries) != 2 || contents.Entries[1].Page != "9" {
+		t.Fatalf("expected tab-separated pages inside contents, got %#v", doc.Blocks)
+	}
+}
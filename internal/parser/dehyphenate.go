@@ -0,0 +1,191 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+)
+
+// compoundSuffixes are particles that keep their hyphen when they start the
+// next line: кто-то, где-либо, хто-небудь.
+var compoundSuffixes = map[string]bool{
+	"то": true, "либо": true, "нибудь": true, "таки": true, "ка": true, "де": true,
+	"небудь": true, "бо": true, "от": true, "но": true,
+}
+
+// compoundPrefixes keep their hyphen when they end the line: кое-что,
+// вице-президент, well-known. compoundPairs are the compounds of a prefix
+// that otherwise starts plain words: из-за but известный.
+var (
+	compoundPrefixes = map[string]bool{
+		"кое": true, "вице": true, "унтер": true, "лейб": true, "хтозна": true,
+		"well": true,
+	}
+	compoundPairs = map[string]bool{"из-за": true, "из-под": true}
+)
+
+// ambiguousPrefixes start compounds as often as plain words: экс-президент
+// and экспорт, будь-який and будьте, self-made and selfish. They keep the
+// hyphen and are reported.
+var ambiguousPrefixes = map[string]bool{
+	"экс": true, "кой": true, "обер": true, "штаб": true, "будь": true,
+	"казна": true, "self": true, "ill": true, "half": true,
+}
+
+// adverbEndings mark по-русски, по-моему, по-своему after "по-";
+// adverbExceptions are the plain words that share those endings.
+var (
+	adverbEndings    = []string{"ски", "цки", "ому", "ему", "ьи"}
+	adverbExceptions = map[string]bool{"тому": true, "этому": true, "чему": true, "кому": true}
+)
+
+// ordinalAdverbs follow в-/во-/по-: во-первых, в-третьих, по-друге.
+var ordinalAdverbs = map[string]bool{
+	"первых": true, "вторых": true, "третьих": true, "четвертых": true, "четвёртых": true,
+	"пятых": true, "шестых": true, "седьмых": true, "восьмых": true, "девятых": true,
+	"десятых": true, "перше": true, "друге": true, "третє": true, "четверте": true,
+	"п'яте": true, "п’яте": true,
+}
+
+// dehyphenateLines repairs words broken across lines with a hyphen: the
+// first word of the next line moves up, and the hyphen is dropped when a
+// lowercase continuation doesn't form a compound. Capitalized and numeric
+// continuations (Нью-Йорк, 1990-2000, 5-ти) keep the hyphen. Guesses are reported
+// as DEHYPHENATE_AMBIGUOUS.
+func dehyphenateLines(lines []string, lineNums []int, cols []int) ([]string, []int, []int, []ast.Diag) {
+	lines = append([]string(nil), lines...)
+	cols = append([]int(nil), cols...)
+	outLines := make([]string, 0, len(lines))
+	outNums := make([]int, 0, len(lines))
+	outCols := make([]int, 0, len(lines))
+	var diags []ast.Diag
+
+	for i := 0; i < len(lines); i++ {
+		cur, num, col := lines[i], lineNums[i], cols[i]
+		for i+1 < len(lines) {
+			head, ok := splitTrailingHyphen(cur)
+			if !ok {
+				break
+			}
+			next, nextCol := trimLeftWithCol(lines[i+1])
+			word := next
+			if end := strings.IndexFunc(next, unicode.IsSpace); end >= 0 {
+				word = next[:end]
+			}
+			tail := leadingLetters(word)
+			prefix := trailingLetters(head)
+			last, _ := utf8.DecodeLastRuneInString(head)
+			first, _ := utf8.DecodeRuneInString(word)
+			var keep, ambiguous bool
+			switch {
+			case unicode.IsDigit(last) && (unicode.IsDigit(first) || tail != ""):
+				keep = true
+			case tail == "" || prefix == "":
+				// "пере-\n«нос»": not a broken word.
+				ok = false
+			case unicode.IsUpper(first):
+				keep = true
+			case strings.HasSuffix(strings.TrimSuffix(head, prefix), "-"):
+				// mother-in-\nlaw: the word already has a hyphen.
+				keep = true
+			default:
+				keep, ambiguous = keepCompoundHyphen(prefix, tail)
+			}
+			if !ok {
+				break
+			}
+			if ambiguous {
+				msg := fmt.Sprintf("joined %q across lines as %q", prefix+"-"+tail, prefix+tail)
+				if keep {
+					msg = fmt.Sprintf("kept %q across lines as a compound, not %q", prefix+"-"+tail, prefix+tail)
+				}
+				diags = append(diags, ast.Diag{
+					Pos:     ast.Pos{Line: num, Col: col + utf8.RuneCountInString(cur) - 1},
+					Code:    "DEHYPHENATE_AMBIGUOUS",
+					Message: msg,
+				})
+			}
+			if keep {
+				head += "-"
+			}
+			cur = head + word
+
+			rest, restCol := trimLeftWithCol(next[len(word):])
+			if rest == "" {
+				i++
+				continue
+			}
+			lines[i+1] = rest
+			cols[i+1] += nextCol - 1 + utf8.RuneCountInString(word) + restCol - 1
+			break
+		}
+		outLines = append(outLines, cur)
+		outNums = append(outNums, num)
+		outCols = append(outCols, col)
+	}
+	return outLines, outNums, outCols, diags
+}
+
+// splitTrailingHyphen reports a line that ends with a word and a hyphen and
+// returns the line without it.
+func splitTrailingHyphen(line string) (string, bool) {
+	line = strings.TrimRightFunc(line, unicode.IsSpace)
+	r, size := utf8.DecodeLastRuneInString(line)
+	if r != '-' && r != '\u2010' {
+		return "", false
+	}
+	head := line[:len(line)-size]
+	if prev, _ := utf8.DecodeLastRuneInString(head); !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		return "", false
+	}
+	return head, true
+}
+
+// keepCompoundHyphen decides whether prefix-tail is a hyphenated compound
+// and whether that decision is a guess.
+func keepCompoundHyphen(prefix, tail string) (bool, bool) {
+	p, t := strings.ToLower(prefix), strings.ToLower(tail)
+	if compoundSuffixes[t] || compoundPrefixes[p] || compoundPairs[p+"-"+t] {
+		return true, false
+	}
+	if ambiguousPrefixes[p] {
+		return true, true
+	}
+	if (p == "в" || p == "во" || p == "по") && ordinalAdverbs[t] {
+		return true, false
+	}
+	if (p == "по" || p == "во") && !adverbExceptions[t] {
+		for _, ending := range adverbEndings {
+			if strings.HasSuffix(t, ending) {
+				return true, false
+			}
+		}
+		// по-\nтому may be "потому" or a broken compound.
+		return false, true
+	}
+	return false, false
+}
+
+func leadingLetters(s string) string {
+	for i, r := range s {
+		if !unicode.IsLetter(r) {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+func trailingLetters(s string) string {
+	i := len(s)
+	for i > 0 {
+		r, size := utf8.DecodeLastRuneInString(s[:i])
+		if !unicode.IsLetter(r) {
+			break
+		}
+		i -= size
+	}
+	return s[i:]
+}
@@ -0,0 +1,154 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+)
+
+const (
+	maxEpigraphLines            = 8
+	maxEpigraphAttributionWords = 10
+)
+
+// parseEpigraphCandidates detects an epigraph at the start of candidates: a
+// short quotation and a `— Author, Work` line, either in one candidate or as
+// the quotation followed by a separate attribution candidate. It reports how
+// many candidates were consumed.
+func parseEpigraphCandidates(candidates []candidate) (ast.Epigraph, []ast.Diag, int, bool) {
+	c := candidates[0]
+	quote := candidate{lines: c.lines, lineNums: c.lineNums}
+	consumed := 1
+	var attrLine string
+	var attrNum int
+	switch {
+	case len(c.lines) >= 2:
+		attrLine, attrNum = c.lines[len(c.lines)-1], c.lineNums[len(c.lines)-1]
+		quote = candidate{lines: c.lines[:len(c.lines)-1], lineNums: c.lineNums[:len(c.lines)-1]}
+	case len(candidates) >= 2 && len(candidates[1].lines) == 1:
+		attrLine, attrNum = candidates[1].lines[0], candidates[1].lineNums[0]
+		consumed = 2
+	default:
+		return ast.Epigraph{}, nil, 0, false
+	}
+
+	attr, attrCol, ok := parseEpigraphAttribution(attrLine)
+	if !ok || len(quote.lines) > maxEpigraphLines {
+		return ast.Epigraph{}, nil, 0, false
+	}
+	for _, line := range quote.lines {
+		if isDialogueLine(line) || isEpigraphDashLine(line) {
+			return ast.Epigraph{}, nil, 0, false
+		}
+	}
+	// "— Пока." or "— Маша, привет" after a line of narration is a dialogue
+	// turn; an epigraph sets its quotation apart or names an author.
+	quoted := looksLikeQuotation(quote.lines)
+	if !quoted && (endsLikeSentence(attr) || !looksLikeEpigraphAuthor(attr)) {
+		return ast.Epigraph{}, nil, 0, false
+	}
+
+	var epigraph ast.Epigraph
+	var diags []ast.Diag
+	if stanza, stanzaDiags, ok := parseVerseStanza(quote, true); ok {
+		epigraph.Lines, diags = stanza.Lines, stanzaDiags
+	} else {
+		lines := make([]string, len(quote.lines))
+		cols := make([]int, len(quote.lines))
+		for i, line := range quote.lines {
+			lines[i], cols[i] = trimLeftWithCol(line)
+		}
+		epigraph.In, diags = parseInlineLinesWithCols(lines, quote.lineNums, cols, true)
+	}
+	attrIn, attrDiags := parseInlineLinesWithCols([]string{attr}, []int{attrNum}, []int{attrCol}, false)
+	epigraph.Attribution = attrIn
+	return epigraph, append(diags, attrDiags...), consumed, true
+}
+
+func parseEpigraphAttribution(line string) (string, int, bool) {
+	if !isEpigraphDashLine(line) {
+		return "", 0, false
+	}
+	trimmed, col := trimLeftWithCol(line)
+	_, size := utf8.DecodeRuneInString(trimmed)
+	rest, restCol := trimLeftWithCol(trimmed[size:])
+	text := strings.TrimSpace(rest)
+	if text == "" || len(strings.Fields(text)) > maxEpigraphAttributionWords || endsLikeSpeech(text) {
+		return "", 0, false
+	}
+	r, _ := utf8.DecodeRuneInString(text)
+	if !unicode.IsUpper(r) && r != '«' && r != '"' {
+		return "", 0, false
+	}
+	return text, col + restCol, true
+}
+
+// looksLikeEpigraphAuthor reports an attribution that starts with a name,
+// "Л. Толстой", or with one word and a work after the comma, "Шекспир,
+// «Гамлет»". The work starts with a capital, a quote or a year.
+func looksLikeEpigraphAuthor(attr string) bool {
+	name, work, hasWork := strings.Cut(attr, ",")
+	if hasWork {
+		r, _ := utf8.DecodeRuneInString(strings.TrimSpace(work))
+		if !unicode.IsUpper(r) && !unicode.IsDigit(r) && !strings.ContainsRune("«\"“„", r) {
+			return false
+		}
+	}
+	if looksLikeAuthorName(name) {
+		return true
+	}
+	words := strings.Fields(name)
+	if !hasWork || len(words) != 1 {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(words[0])
+	return unicode.IsUpper(r) && !titleNameStopWords[strings.ToLower(words[0])]
+}
+
+func isEpigraphDashLine(line string) bool {
+	t := strings.TrimLeft(line, " \t")
+	r, size := utf8.DecodeRuneInString(t)
+	if r != '—' && r != '–' && r != '-' {
+		return false
+	}
+	next, _ := utf8.DecodeRuneInString(t[size:])
+	return unicode.IsSpace(next)
+}
+
+// looksLikeQuotation reports a quotation that is indented, italic or quoted.
+func looksLikeQuotation(lines []string) bool {
+	indented := true
+	for _, line := range lines {
+		if !hasLeadingIndent(line) {
+			indented = false
+		}
+	}
+	if indented {
+		return true
+	}
+	text := strings.TrimSpace(strings.Join(lines, " "))
+	first, _ := utf8.DecodeRuneInString(text)
+	last, _ := utf8.DecodeLastRuneInString(text)
+	switch first {
+	case '_', '*':
+		return last == first
+	case '«':
+		return strings.ContainsRune(text, '»')
+	case '"', '“', '„':
+		return true
+	}
+	return false
+}
+
+// opensEpigraph reports blocks an epigraph may follow: the title, a heading
+// or another epigraph.
+func opensEpigraph(blk ast.Block) bool {
+	switch blk.(type) {
+	case ast.TitleBlock, ast.Heading, ast.Epigraph:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,291 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+)
+
+var (
+	footnoteDefRe   = regexp.MustCompile(`^[ \t]*(?:\[(\d{1,3}|\*{1,3})\][ \t]+|([¹²³⁴⁵⁶⁷⁸⁹⁰]+)[ \t]*|(\*{1,3})[ \t]+)(\S.*)$`)
+	footnoteLabelRe = regexp.MustCompile(`^(?:\d{1,3}|\*{1,3})$`)
+)
+
+var superscriptDigits = strings.NewReplacer(
+	"⁰", "0", "¹", "1", "²", "2", "³", "3", "⁴", "4",
+	"⁵", "5", "⁶", "6", "⁷", "7", "⁸", "8", "⁹", "9",
+)
+
+func parseFootnoteDefLine(line string) (string, string, int, bool) {
+	m := footnoteDefRe.FindStringSubmatch(line)
+	if m == nil || isSceneBreak(line) {
+		return "", "", 0, false
+	}
+	if m[3] != "" && strings.HasSuffix(strings.TrimSpace(m[4]), "*") {
+		// "* ЧАСТЬ ПЕРВАЯ *" is a decorated heading.
+		return "", "", 0, false
+	}
+	marker := strings.TrimSpace(line[:len(line)-len(m[4])])
+	col := utf8.RuneCountInString(line[:len(line)-len(m[4])]) + 1
+	return marker, m[4], col, true
+}
+
+// footnoteLabel normalizes a marker to the label refs and defs share.
+func footnoteLabel(marker string) string {
+	return superscriptDigits.Replace(strings.Trim(marker, "[]"))
+}
+
+// parseFootnoteCandidate turns a candidate whose first line starts with a
+// footnote label (`[1] `, `¹`, `* `) into definitions; lines without a label
+// continue the previous definition.
+func parseFootnoteCandidate(c candidate) ([]ast.Block, []ast.Diag, bool) {
+	if _, _, _, ok := parseFootnoteDefLine(c.lines[0]); !ok {
+		return nil, nil, false
+	}
+
+	type defLines struct {
+		marker   string
+		lines    []string
+		lineNums []int
+		cols     []int
+	}
+	var defs []defLines
+	for i, line := range c.lines {
+		if marker, text, col, ok := parseFootnoteDefLine(line); ok {
+			defs = append(defs, defLines{marker: marker, lines: []string{text}, lineNums: []int{c.lineNums[i]}, cols: []int{col}})
+			continue
+		}
+		last := &defs[len(defs)-1]
+		trimmed, col := trimLeftWithCol(line)
+		last.lines = append(last.lines, trimmed)
+		last.lineNums = append(last.lineNums, c.lineNums[i])
+		last.cols = append(last.cols, col)
+	}
+
+	seen := make(map[string]bool, len(defs))
+	for _, d := range defs {
+		label := footnoteLabel(d.marker)
+		if seen[label] {
+			// "* a\n* b" is a bulleted list, not two notes sharing a label.
+			return nil, nil, false
+		}
+		seen[label] = true
+	}
+
+	blocks := make([]ast.Block, 0, len(defs))
+	var diags []ast.Diag
+	for _, d := range defs {
+		in, inDiags := parseInlineLinesWithCols(d.lines, d.lineNums, d.cols, true)
+		blocks = append(blocks, ast.FootnoteDef{Label: footnoteLabel(d.marker), Marker: d.marker, In: in})
+		diags = append(diags, inDiags...)
+	}
+	return blocks, diags, true
+}
+
+// buildFootnoteRefNodes recognizes references attached to the preceding
+// text: `word[1]`, `word*` and superscript digits after a word.
+func buildFootnoteRefNodes(nodes []node) []node {
+	for i, n := range nodes {
+		if n.kind != nodeParenSpan && n.kind != nodeQuoteSpan && n.kind != nodeEmphasis {
+			continue
+		}
+		nodes[i].children = buildFootnoteRefNodes(n.children)
+	}
+
+	for i, n := range nodes {
+		if i == 0 || nodes[i-1].kind == nodeSpace {
+			continue
+		}
+		prev := nodes[i-1]
+		switch n.kind {
+		case nodeParenSpan:
+			if n.open != '[' || len(n.children) != 1 {
+				continue
+			}
+			label := n.children[0].text
+			if (n.children[0].kind == nodeWord || n.children[0].kind == nodeEmphasisMark) && footnoteLabelRe.MatchString(label) {
+				nodes[i] = node{kind: nodeFootnoteRef, text: "[" + label + "]", pos: n.pos}
+			}
+		case nodeEmphasisMark:
+			if n.ch != '*' || len(n.text) > 3 || (prev.kind != nodeWord && prev.kind != nodePunct && prev.kind != nodeQuoteSpan) {
+				continue
+			}
+			if i+1 < len(nodes) && nodes[i+1].kind != nodeSpace && nodes[i+1].kind != nodePunct {
+				continue
+			}
+			nodes[i] = node{kind: nodeFootnoteRef, text: n.text, pos: n.pos}
+		case nodeWord:
+			if !isSuperscriptNumber(n.text) {
+				continue
+			}
+			if prev.kind == nodeWord && utf8.RuneCountInString(prev.text) < 3 {
+				continue
+			}
+			if prev.kind != nodeWord && prev.kind != nodePunct && prev.kind != nodeQuoteSpan {
+				continue
+			}
+			nodes[i] = node{kind: nodeFootnoteRef, text: n.text, pos: n.pos}
+		}
+	}
+	return nodes
+}
+
+func isSuperscriptNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	return strings.Trim(s, "⁰¹²³⁴⁵⁶⁷⁸⁹") == ""
+}
+
+// footnoteKey names one note: a label and the scope of its definition.
+type footnoteKey struct {
+	label string
+	scope int
+}
+
+// scopeFootnotes numbers the definitions that reuse a label and points each
+// reference at the first definition after it with that label, or at the last
+// one when none follows. A definition opens a new scope only when the label
+// was referred to since the previous one, so notes numbered anew in each
+// chapter stay apart and a label defined twice in a row shares its scope.
+func scopeFootnotes(blocks []ast.Block) {
+	remaining := make(map[string]int)
+	for _, blk := range blocks {
+		if def, ok := blk.(ast.FootnoteDef); ok {
+			remaining[def.Label]++
+		}
+	}
+
+	seen := make(map[string]int)
+	pending := make(map[string]bool)
+	refs := make(map[footnoteKey]int)
+	var scopeRefs func([]ast.Inline) []ast.Inline
+	scopeRefs = func(in []ast.Inline) []ast.Inline {
+		for i, item := range in {
+			switch it := item.(type) {
+			case ast.FootnoteRef:
+				it.Scope = max(seen[it.Label], 1)
+				if remaining[it.Label] > 0 {
+					it.Scope = seen[it.Label] + 1
+				}
+				pending[it.Label] = true
+				key := footnoteKey{label: it.Label, scope: it.Scope}
+				refs[key]++
+				it.N = refs[key]
+				in[i] = it
+			case ast.QuoteSpan:
+				it.In = scopeRefs(it.In)
+				in[i] = it
+			case ast.ParenSpan:
+				it.In = scopeRefs(it.In)
+				in[i] = it
+			case ast.Emphasis:
+				it.In = scopeRefs(it.In)
+				in[i] = it
+			}
+		}
+		return in
+	}
+	for i, blk := range blocks {
+		blk = ast.MapInlines(blk, scopeRefs)
+		if def, ok := blk.(ast.FootnoteDef); ok {
+			if seen[def.Label] == 0 || pending[def.Label] {
+				seen[def.Label]++
+			}
+			pending[def.Label] = false
+			remaining[def.Label]--
+			def.Scope = seen[def.Label]
+			blk = def
+		}
+		blocks[i] = blk
+	}
+}
+
+// checkFootnotes reports references without definitions, definitions nobody
+// refers to and labels defined twice in one scope. blockLines holds the first
+// source line of each block.
+func checkFootnotes(blocks []ast.Block, blockLines []int) []ast.Diag {
+	refs := make(map[footnoteKey]int)
+	var refOrder []footnoteKey
+	defs := make(map[footnoteKey]int)
+	var defOrder []footnoteKey
+	labels := make(map[string]bool)
+	var diags []ast.Diag
+
+	for i, blk := range blocks {
+		line := 0
+		if i < len(blockLines) {
+			line = blockLines[i]
+		}
+		if def, ok := blk.(ast.FootnoteDef); ok {
+			key := footnoteKey{label: def.Label, scope: def.Scope}
+			if first, seen := defs[key]; seen {
+				diags = append(diags, ast.Diag{
+					Pos:     ast.Pos{Line: line, Col: 1},
+					Code:    "FOOTNOTE_DUPLICATE",
+					Message: fmt.Sprintf("footnote %q is defined again (first at line %d)", def.Label, first),
+				})
+			} else {
+				defs[key] = line
+				defOrder = append(defOrder, key)
+			}
+			labels[def.Label] = true
+		}
+		for _, ref := range blockFootnoteRefs(blk) {
+			key := footnoteKey{label: ref.Label, scope: ref.Scope}
+			if _, seen := refs[key]; !seen {
+				refs[key] = line
+				refOrder = append(refOrder, key)
+			}
+		}
+	}
+
+	for _, key := range refOrder {
+		if !labels[key.label] {
+			diags = append(diags, ast.Diag{
+				Pos:     ast.Pos{Line: refs[key], Col: 1},
+				Code:    "FOOTNOTE_UNDEFINED",
+				Message: fmt.Sprintf("footnote reference %q has no definition", key.label),
+			})
+		}
+	}
+	for _, key := range defOrder {
+		if _, ok := refs[key]; !ok {
+			diags = append(diags, ast.Diag{
+				Pos:     ast.Pos{Line: defs[key], Col: 1},
+				Code:    "FOOTNOTE_UNUSED",
+				Message: fmt.Sprintf("footnote %q is never referenced", key.label),
+			})
+		}
+	}
+	return diags
+}
+
+func blockFootnoteRefs(blk ast.Block) []ast.FootnoteRef {
+	var out []ast.FootnoteRef
+	ast.MapInlines(blk, func(in []ast.Inline) []ast.Inline {
+		out = append(out, inlineFootnoteRefs(in)...)
+		return in
+	})
+	return out
+}
+
+func inlineFootnoteRefs(in []ast.Inline) []ast.FootnoteRef {
+	var out []ast.FootnoteRef
+	for _, item := range in {
+		switch it := item.(type) {
+		case ast.FootnoteRef:
+			out = append(out, it)
+		case ast.QuoteSpan:
+			out = append(out, inlineFootnoteRefs(it.In)...)
+		case ast.ParenSpan:
+			out = append(out, inlineFootnoteRefs(it.In)...)
+		case ast.Emphasis:
+			out = append(out, inlineFootnoteRefs(it.In)...)
+		}
+	}
+	return out
+}
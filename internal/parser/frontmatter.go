@@ -0,0 +1,122 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+)
+
+var (
+	frontMatterKeyRe  = regexp.MustCompile(`^([\p{L}\p{N}_][\p{L}\p{N}_.-]*)[ \t]*:(?:[ \t]+(.*))?$`)
+	frontMatterItemRe = regexp.MustCompile(`^[ \t]*-[ \t]+(.*)$`)
+)
+
+// splitFrontMatter cuts a leading `---` YAML block off the source lines.
+// Only flat `key: value` pairs and lists of scalars are read; a block with
+// anything else is reported and left in the text.
+func splitFrontMatter(lines []sourceLine) ([]ast.MetaField, []ast.Diag, []sourceLine) {
+	if len(lines) == 0 || strings.TrimRight(lines[0].text, " \t") != "---" {
+		return nil, nil, lines
+	}
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		t := strings.TrimRight(lines[i].text, " \t")
+		if t == "---" || t == "..." {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return nil, nil, lines
+	}
+
+	body := lines[1:end]
+	texts := make([]string, len(body))
+	nums := make([]int, len(body))
+	for i, ln := range body {
+		texts[i] = ln.text
+		nums[i] = ln.line
+	}
+	// "---" around prose is a pair of scene breaks, not metadata.
+	for _, t := range texts {
+		if strings.TrimSpace(t) == "" || strings.HasPrefix(strings.TrimSpace(t), "#") {
+			continue
+		}
+		if !frontMatterKeyRe.MatchString(t) {
+			return nil, nil, lines
+		}
+		break
+	}
+
+	fields, diags := parseFrontMatterLines(texts, nums)
+	if len(diags) > 0 {
+		return nil, diags, lines
+	}
+	if len(fields) == 0 {
+		return nil, nil, lines
+	}
+	return fields, nil, lines[end+1:]
+}
+
+func parseFrontMatterLines(lines []string, lineNums []int) ([]ast.MetaField, []ast.Diag) {
+	var fields []ast.MetaField
+	var diags []ast.Diag
+	for i, line := range lines {
+		t := strings.TrimSpace(line)
+		if t == "" || strings.HasPrefix(t, "#") {
+			continue
+		}
+		if m := frontMatterKeyRe.FindStringSubmatch(line); m != nil {
+			field := ast.MetaField{Key: m[1]}
+			value := strings.TrimSpace(m[2])
+			if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+				for _, item := range strings.Split(value[1:len(value)-1], ",") {
+					if item = unquoteYAML(strings.TrimSpace(item)); item != "" {
+						field.Items = append(field.Items, item)
+					}
+				}
+			} else {
+				field.Value = unquoteYAML(value)
+			}
+			fields = append(fields, field)
+			continue
+		}
+		if len(fields) > 0 {
+			last := &fields[len(fields)-1]
+			if m := frontMatterItemRe.FindStringSubmatch(line); m != nil && last.Value == "" {
+				last.Items = append(last.Items, unquoteYAML(strings.TrimSpace(m[1])))
+				continue
+			}
+			if line != t && len(last.Items) == 0 {
+				// An indented line folds into the value above it.
+				last.Value = strings.TrimSpace(last.Value + " " + t)
+				continue
+			}
+		}
+		diags = append(diags, ast.Diag{
+			Pos:     ast.Pos{Line: lineNums[i], Col: 1},
+			Code:    "FRONT_MATTER_INVALID",
+			Message: fmt.Sprintf("unsupported front matter line %q, block kept as text", t),
+		})
+	}
+	return fields, diags
+}
+
+func unquoteYAML(s string) string {
+	if len(s) < 2 {
+		return s
+	}
+	switch {
+	case s[0] == '"' && s[len(s)-1] == '"':
+		if u, err := strconv.Unquote(s); err == nil {
+			return u
+		}
+		return s[1 : len(s)-1]
+	case s[0] == '\'' && s[len(s)-1] == '\'':
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+	return s
+}
@@ -1,11 +1,14 @@
 package parser
 
 import (
+	"regexp"
 	"unicode"
 
 	"github.com/n0madic/txtfmt/internal/ast"
 )
 
+var feetInchesRe = regexp.MustCompile(`^\d{1,2}['’]\d{1,2}$`)
+
 type tokenKind int
 
 const (
@@ -17,6 +20,8 @@ const (
 	tokenQuote
 	tokenParenOpen
 	tokenParenClose
+	tokenVerbatim
+	tokenEmphasis
 )
 
 type token struct {
@@ -24,6 +29,7 @@ type token struct {
 	text     string
 	ch       rune
 	dashKind ast.DashKind
+	verbatim ast.VerbatimKind
 	pos      ast.Pos
 }
 
@@ -76,6 +82,14 @@ func tokenizeInline(s string, line, startCol int) []token {
 			continue
 		}
 
+		if kind, n, ok := matchVerbatim(runes, i); ok {
+			out = append(out, token{kind: tokenVerbatim, verbatim: kind, text: string(runes[i : i+n]), pos: pos})
+			i += n
+			col += n
+			off += n
+			continue
+		}
+
 		if r == '.' && i+2 < len(runes) && runes[i+1] == '.' && runes[i+2] == '.' {
 			out = append(out, token{kind: tokenEllipsis, text: "...", pos: pos})
 			i += 3
@@ -92,6 +106,15 @@ func tokenizeInline(s string, line, startCol int) []token {
 			continue
 		}
 
+		// Typewriter "--" and "---" stand for one em dash.
+		if n := hyphenRunLength(runes, i); n == 2 || n == 3 {
+			out = append(out, token{kind: tokenDash, ch: '—', dashKind: ast.DashEmDash, text: string(runes[i : i+n]), pos: pos})
+			i += n
+			col += n
+			off += n
+			continue
+		}
+
 		switch r {
 		case '-', '–', '—':
 			out = append(out, token{kind: tokenDash, ch: r, dashKind: dashKindFromRune(r), text: string(r), pos: pos})
@@ -125,6 +148,14 @@ func tokenizeInline(s string, line, startCol int) []token {
 			continue
 		}
 
+		if n := emphasisRunLength(runes, i); n > 0 {
+			out = append(out, token{kind: tokenEmphasis, ch: r, text: string(runes[i : i+n]), pos: pos})
+			i += n
+			col += n
+			off += n
+			continue
+		}
+
 		if _, ok := punctRunes[r]; ok {
 			out = append(out, token{kind: tokenPunct, ch: r, text: string(r), pos: pos})
 			i++
@@ -133,6 +164,14 @@ func tokenizeInline(s string, line, startCol int) []token {
 			continue
 		}
 
+		if isInchMark(runes, i, out) {
+			out[len(out)-1].text += string(r)
+			i++
+			col++
+			off++
+			continue
+		}
+
 		if _, ok := quoteRunes[r]; ok {
 			out = append(out, token{kind: tokenQuote, ch: r, text: string(r), pos: pos})
 			i++
@@ -191,6 +230,12 @@ func isWordRune(runes []rune, i int) bool {
 	if r == '\'' || r == '’' || r == '№' {
 		return true
 	}
+	if r == '_' {
+		if i == 0 || i+1 >= len(runes) {
+			return false
+		}
+		return isAlnum(runes[i-1]) && isAlnum(runes[i+1])
+	}
 	if r == '-' {
 		if i == 0 || i+1 >= len(runes) {
 			return false
@@ -200,6 +245,59 @@ func isWordRune(runes []rune, i int) bool {
 	return false
 }
 
+// emphasisRunLength returns the length of a `*`, `_` or `~~` delimiter run
+// that may open or close emphasis, or 0 when the run is surrounded by spaces
+// or is an intraword underscore.
+func emphasisRunLength(runes []rune, i int) int {
+	r := runes[i]
+	if r != '*' && r != '_' && r != '~' {
+		return 0
+	}
+	n := 0
+	for i+n < len(runes) && runes[i+n] == r {
+		n++
+	}
+	if r == '~' && n != 2 {
+		return 0
+	}
+	prevSpace := i == 0 || unicode.IsSpace(runes[i-1])
+	nextSpace := i+n >= len(runes) || unicode.IsSpace(runes[i+n])
+	if prevSpace && nextSpace {
+		return 0
+	}
+	if r == '_' && i > 0 && i+n < len(runes) && isAlnum(runes[i-1]) && isAlnum(runes[i+n]) {
+		return 0
+	}
+	return n
+}
+
+func isAlnum(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// isInchMark reports whether a double quote closes a feet-and-inches value
+// such as 5'10" and therefore belongs to the preceding word.
+func isInchMark(runes []rune, i int, out []token) bool {
+	if runes[i] != '"' && runes[i] != '”' {
+		return false
+	}
+	if len(out) == 0 || out[len(out)-1].kind != tokenWord || !feetInchesRe.MatchString(out[len(out)-1].text) {
+		return false
+	}
+	if i+1 < len(runes) && (unicode.IsLetter(runes[i+1]) || unicode.IsDigit(runes[i+1])) {
+		return false
+	}
+	return true
+}
+
+func hyphenRunLength(runes []rune, i int) int {
+	n := 0
+	for i+n < len(runes) && runes[i+n] == '-' {
+		n++
+	}
+	return n
+}
+
 func dashKindFromRune(r rune) ast.DashKind {
 	switch r {
 	case '–':
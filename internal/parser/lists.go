@@ -0,0 +1,306 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+)
+
+var listItemRe = regexp.MustCompile(`^([ \t]*)([•◦▪‣·*–-]|\d{1,3}[.)]|[a-zа-яієїґ]\))[ \t]+(\S.*)$`)
+
+const maxShortListItemWords = 8
+
+type listLine struct {
+	indent int
+	marker string
+	text   string
+	col    int
+}
+
+type listItemLines struct {
+	listLine
+	lines    []string
+	lineNums []int
+	cols     []int
+}
+
+func parseListItemLine(line string) (listLine, bool) {
+	m := listItemRe.FindStringSubmatch(line)
+	if m == nil {
+		return listLine{}, false
+	}
+	return listLine{
+		indent: indentWidth(m[1]),
+		marker: m[2],
+		text:   m[3],
+		col:    utf8.RuneCountInString(line[:len(line)-len(m[3])]) + 1,
+	}, true
+}
+
+func indentWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		if r == '\t' {
+			width += 4
+			continue
+		}
+		width++
+	}
+	return width
+}
+
+// parseListCandidate detects a list inside a candidate: an optional intro
+// ending with a colon, the items with their wrapped continuation lines and
+// an optional trailing paragraph. afterColon reports that the previous
+// candidate ended with a colon.
+func parseListCandidate(c candidate, afterColon bool) ([]ast.Block, []ast.Diag, bool) {
+	first := -1
+	var firstItem listLine
+	for i, line := range c.lines {
+		if it, ok := parseListItemLine(line); ok {
+			first = i
+			firstItem = it
+			break
+		}
+	}
+	if first < 0 {
+		return nil, nil, false
+	}
+	if first > 0 {
+		intro := strings.TrimSpace(c.lines[first-1])
+		switch {
+		case strings.HasSuffix(intro, ":"):
+			afterColon = true
+		case firstItem.marker == "-" || firstItem.marker == "–" || firstItem.marker == "*":
+			return nil, nil, false
+		}
+	}
+
+	items := make([]listItemLines, 0, len(c.lines)-first)
+	end := first
+	for end < len(c.lines) {
+		line := c.lines[end]
+		if it, ok := parseListItemLine(line); ok {
+			items = append(items, listItemLines{
+				listLine: it,
+				lines:    []string{it.text},
+				lineNums: []int{c.lineNums[end]},
+				cols:     []int{it.col},
+			})
+			end++
+			continue
+		}
+		last := &items[len(items)-1]
+		trimmed, col := trimLeftWithCol(line)
+		if indentWidth(line[:len(line)-len(trimmed)]) <= last.indent && !startsLikelyContinuation(line) {
+			break
+		}
+		last.lines = append(last.lines, trimmed)
+		last.lineNums = append(last.lineNums, c.lineNums[end])
+		last.cols = append(last.cols, col)
+		end++
+	}
+
+	if !looksLikeList(items, afterColon) {
+		return nil, nil, false
+	}
+
+	var blocks []ast.Block
+	var diags []ast.Diag
+	if first > 0 {
+		introBlocks, introDiags, _ := parseCandidate(candidate{lines: c.lines[:first], lineNums: c.lineNums[:first]})
+		blocks = append(blocks, introBlocks...)
+		diags = append(diags, introDiags...)
+	}
+
+	list, listDiags := buildListBlock(items)
+	blocks = append(blocks, list)
+	diags = append(diags, listDiags...)
+
+	if end < len(c.lines) {
+		rest := candidate{lines: c.lines[end:], lineNums: c.lineNums[end:]}
+		restBlocks, restDiags, ok := parseListCandidate(rest, false)
+		if !ok {
+			restBlocks, restDiags, _ = parseCandidate(rest)
+		}
+		blocks = append(blocks, restBlocks...)
+		diags = append(diags, restDiags...)
+	}
+	return blocks, diags, true
+}
+
+func buildListBlock(items []listItemLines) (ast.ListBlock, []ast.Diag) {
+	indents := make([]int, 0, len(items))
+	for _, it := range items {
+		indents = insertSortedUnique(indents, it.indent)
+	}
+
+	list := ast.ListBlock{Kind: listKindForMarker(items[0].marker)}
+	var diags []ast.Diag
+	for _, it := range items {
+		level := 1
+		for i, indent := range indents {
+			if indent == it.indent {
+				level = i + 1
+				break
+			}
+		}
+		in, inDiags := parseInlineLinesWithCols(it.lines, it.lineNums, it.cols, true)
+		list.Items = append(list.Items, ast.ListItem{
+			Level:  level,
+			Kind:   listKindForMarker(it.marker),
+			Marker: it.marker,
+			In:     in,
+		})
+		diags = append(diags, inDiags...)
+	}
+	return list, diags
+}
+
+func insertSortedUnique(xs []int, v int) []int {
+	for i, x := range xs {
+		if x == v {
+			return xs
+		}
+		if x > v {
+			xs = append(xs, 0)
+			copy(xs[i+1:], xs[i:])
+			xs[i] = v
+			return xs
+		}
+	}
+	return append(xs, v)
+}
+
+func listKindForMarker(marker string) ast.ListKind {
+	r, _ := utf8.DecodeLastRuneInString(marker)
+	if r == '.' || r == ')' {
+		return ast.ListOrdered
+	}
+	return ast.ListUnordered
+}
+
+// looksLikeList tells a list from dialogue or a paragraph that happens to
+// start with a number. Bullets are always lists; numbered items must count
+// up; hyphen items need a colon before them or list-like item text.
+func looksLikeList(items []listItemLines, afterColon bool) bool {
+	if len(items) == 0 {
+		return false
+	}
+	top := items[0].indent
+	for _, it := range items {
+		top = min(top, it.indent)
+	}
+
+	var hyphens, stars, ordered []listItemLines
+	for _, it := range items {
+		switch {
+		case it.marker == "-" || it.marker == "–":
+			hyphens = append(hyphens, it)
+		case it.marker == "*":
+			stars = append(stars, it)
+		case listKindForMarker(it.marker) == ast.ListOrdered:
+			if it.indent == top {
+				ordered = append(ordered, it)
+			}
+		}
+	}
+
+	if len(stars) == 1 && len(items) == 1 {
+		return false
+	}
+	if len(ordered) > 0 && !isOrderedSequence(ordered) {
+		return false
+	}
+	if len(hyphens) > 0 && !hyphenItemsLookLikeList(hyphens, afterColon) {
+		return false
+	}
+	return true
+}
+
+func isOrderedSequence(items []listItemLines) bool {
+	if len(items) < 2 {
+		return false
+	}
+	prev := -1
+	for _, it := range items {
+		n, ok := orderedMarkerValue(it.marker)
+		if !ok {
+			return false
+		}
+		if prev >= 0 && n != prev+1 {
+			return false
+		}
+		prev = n
+	}
+	return true
+}
+
+func orderedMarkerValue(marker string) (int, bool) {
+	body := marker[:len(marker)-1]
+	if n, err := strconv.Atoi(body); err == nil {
+		return n, true
+	}
+	r, size := utf8.DecodeRuneInString(body)
+	if size != len(body) {
+		return 0, false
+	}
+	const cyrillic = "абвгдежзиклмнопрстуфхцчшщэюя"
+	if idx := strings.IndexRune(cyrillic, r); idx >= 0 {
+		return utf8.RuneCountInString(cyrillic[:idx]) + 1, true
+	}
+	const ukrainian = "абвгґдеєжзиіїйклмнопрстуфхцчшщюя"
+	if idx := strings.IndexRune(ukrainian, r); idx >= 0 {
+		return utf8.RuneCountInString(ukrainian[:idx]) + 1, true
+	}
+	if r >= 'a' && r <= 'z' {
+		return int(r-'a') + 1, true
+	}
+	return 0, false
+}
+
+// hyphenItemsLookLikeList keeps "- Привет." lines as dialogue. After a
+// colon any items that aren't all capitalized sentences are a list;
+// otherwise the items must all start lowercase or be more than two short
+// phrases, so "- Да" and "- Нет" stay a dialogue.
+func hyphenItemsLookLikeList(items []listItemLines, afterColon bool) bool {
+	lower := true
+	short := true
+	speech := true
+	for _, it := range items {
+		text := strings.Join(it.lines, " ")
+		r, _ := utf8.DecodeRuneInString(text)
+		if !unicode.IsLower(r) && !unicode.IsDigit(r) {
+			lower = false
+		}
+		if len(strings.Fields(text)) > maxShortListItemWords || endsLikeSentence(text) || strings.ContainsAny(text, "—\"«»“”") {
+			short = false
+		}
+		if !unicode.IsUpper(r) || !(endsLikeSentence(text) || strings.Contains(text, " - ") || strings.Contains(text, " — ")) {
+			speech = false
+		}
+	}
+	if afterColon {
+		return !speech
+	}
+	return len(items) >= 2 && (lower || short && len(items) > 2)
+}
+
+func endsLikeSpeech(s string) bool {
+	r, _ := utf8.DecodeLastRuneInString(strings.TrimSpace(s))
+	return r == '!' || r == '?' || r == '…'
+}
+
+func endsLikeSentence(s string) bool {
+	r, _ := utf8.DecodeLastRuneInString(strings.TrimSpace(s))
+	switch r {
+	case '.', '!', '?', '…':
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,422 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+	"github.com/n0madic/txtfmt/internal/config"
+)
+
+var (
+	mdBlockquoteRe     = regexp.MustCompile(`^ {0,3}> ?`)
+	mdListItemRe       = regexp.MustCompile(`^ {0,3}([-+*]|\d{1,9}[.)])(?:[ \t]+(?:\[[ xX]\][ \t]+)?|$)`)
+	mdFenceRe          = regexp.MustCompile("^(`{3,}|~{3,})")
+	mdThematicBreakRe  = regexp.MustCompile(`^(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	mdSetextRe         = regexp.MustCompile(`^(?:=+|-+)[ \t]*$`)
+	mdATXRe            = regexp.MustCompile(`^#{1,6}(?:[ \t]+|$)`)
+	mdATXCloseRe       = regexp.MustCompile(`[ \t]+#+[ \t]*$|^#+[ \t]*$`)
+	mdLinkRefDefRe     = regexp.MustCompile(`^\[[^\]]+\]:[ \t]*\S`)
+	mdHTMLBlockRe      = regexp.MustCompile(`^<(?:[A-Za-z][A-Za-z0-9-]*(?:[\s/>]|$)|/[A-Za-z]|!--|![A-Z]|!\[CDATA\[|\?)`)
+	mdTableDelimiterRe = regexp.MustCompile(`^\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	mdInlineTagRe      = regexp.MustCompile(`^<(?:[A-Za-z][A-Za-z0-9+.-]{1,31}:[^\s<>]*|[^\s<>@]+@[^\s<>]+|/?[A-Za-z][A-Za-z0-9-]*(?:\s[^<>]*)?/?|!--[\s\S]*?--)>`)
+)
+
+const mdEscapable = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+type mdLine struct {
+	num    int
+	prefix string
+	rest   string
+	ending string
+	indent int
+	item   bool
+}
+
+type mdBuilder struct {
+	doc  *ast.Document
+	raw  strings.Builder
+	para []mdLine
+}
+
+// parseMarkdown splits Markdown source into prose paragraphs that go through
+// the inline tokenizer and raw blocks that keep the remaining syntax intact.
+func parseMarkdown(input string, cfg config.Config) ast.Document {
+	doc := ast.Document{
+		Lang:  cfg.Lang,
+		Style: cfg.Style,
+		Input: config.InputFormatMarkdown,
+	}
+	b := &mdBuilder{doc: &doc}
+
+	rawLines := strings.SplitAfter(input, "\n")
+	lines := make([]mdLine, 0, len(rawLines))
+	for i, raw := range rawLines {
+		if raw == "" {
+			continue
+		}
+		lines = append(lines, splitMarkdownLine(raw, i+1))
+	}
+
+	start := 0
+	if end, ok := markdownFrontMatterEnd(lines); ok {
+		texts := make([]string, 0, end-2)
+		nums := make([]int, 0, end-2)
+		for i, ln := range lines[:end] {
+			b.raw.WriteString(ln.prefix + ln.rest + ln.ending)
+			if i > 0 && i < end-1 {
+				texts = append(texts, ln.prefix+ln.rest)
+				nums = append(nums, ln.num)
+			}
+		}
+		meta, diags := parseFrontMatterLines(texts, nums)
+		doc.Meta = meta
+		doc.Diags = append(doc.Diags, diags...)
+		start = end
+	}
+
+	listIndent := 0
+	for i := start; i < len(lines); i++ {
+		ln := lines[i]
+
+		if m := mdFenceRe.FindString(ln.rest); m != "" {
+			b.flushParagraph()
+			b.raw.WriteString(ln.prefix + ln.rest + ln.ending)
+			for i+1 < len(lines) {
+				i++
+				b.raw.WriteString(lines[i].prefix + lines[i].rest + lines[i].ending)
+				if isMarkdownFenceClose(lines[i].rest, m) {
+					break
+				}
+			}
+			continue
+		}
+
+		if strings.TrimSpace(ln.rest) == "" {
+			b.flushParagraph()
+			b.raw.WriteString(ln.prefix + ln.rest + ln.ending)
+			continue
+		}
+
+		if len(b.para) == 0 && !ln.item && ln.indent >= listIndent+4 {
+			b.raw.WriteString(ln.prefix + ln.rest + ln.ending)
+			continue
+		}
+		if !ln.item && len(b.para) == 0 && ln.indent < listIndent {
+			listIndent = 0
+		}
+		if ln.item {
+			listIndent = ln.indent
+		}
+
+		if len(b.para) > 0 && !ln.item && mdSetextRe.MatchString(ln.rest) {
+			b.flushParagraph()
+			b.raw.WriteString(ln.prefix + ln.rest + ln.ending)
+			continue
+		}
+
+		if mdThematicBreakRe.MatchString(ln.rest) {
+			b.flushParagraph()
+			b.raw.WriteString(ln.prefix + ln.rest + ln.ending)
+			continue
+		}
+
+		if mdHTMLBlockRe.MatchString(ln.rest) && len(b.para) == 0 {
+			for {
+				b.raw.WriteString(lines[i].prefix + lines[i].rest + lines[i].ending)
+				if i+1 >= len(lines) || strings.TrimSpace(lines[i+1].rest) == "" {
+					break
+				}
+				i++
+			}
+			continue
+		}
+
+		if len(b.para) == 0 && mdLinkRefDefRe.MatchString(ln.rest) {
+			b.raw.WriteString(ln.prefix + ln.rest + ln.ending)
+			continue
+		}
+
+		if strings.Contains(ln.rest, "|") && i+1 < len(lines) && isMarkdownTableDelimiter(lines[i+1].rest) {
+			b.flushParagraph()
+			for {
+				b.raw.WriteString(lines[i].prefix + lines[i].rest + lines[i].ending)
+				if i+1 >= len(lines) || !strings.Contains(lines[i+1].rest, "|") {
+					break
+				}
+				i++
+			}
+			continue
+		}
+
+		if m := mdATXRe.FindString(ln.rest); m != "" {
+			b.flushParagraph()
+			b.writeHeading(ln, m)
+			continue
+		}
+
+		if ln.item && len(b.para) > 0 {
+			b.flushParagraph()
+		}
+		b.para = append(b.para, ln)
+	}
+	b.flushParagraph()
+	b.flushRaw()
+
+	return doc
+}
+
+func splitMarkdownLine(raw string, num int) mdLine {
+	ln := mdLine{num: num}
+	content := strings.TrimSuffix(raw, "\n")
+	ln.ending = raw[len(content):]
+	if strings.HasSuffix(content, "\r") {
+		content = strings.TrimSuffix(content, "\r")
+		ln.ending = "\r" + ln.ending
+	}
+
+	for {
+		m := mdBlockquoteRe.FindString(content)
+		if m == "" {
+			break
+		}
+		ln.prefix += m
+		content = content[len(m):]
+	}
+	quoteWidth := len(ln.prefix)
+
+	if !mdThematicBreakRe.MatchString(strings.TrimLeft(content, " \t")) {
+		if m := mdListItemRe.FindString(content); m != "" {
+			ln.prefix += m
+			content = content[len(m):]
+			ln.item = true
+		}
+	}
+
+	trimmed := strings.TrimLeft(content, " \t")
+	lead := content[:len(content)-len(trimmed)]
+	ln.prefix += lead
+	ln.rest = trimmed
+	ln.indent = markdownIndentWidth(ln.prefix[quoteWidth:])
+	if !ln.item {
+		ln.indent = markdownIndentWidth(lead)
+	}
+	return ln
+}
+
+func markdownIndentWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		if r == '\t' {
+			width += 4 - width%4
+			continue
+		}
+		width++
+	}
+	return width
+}
+
+func markdownFrontMatterEnd(lines []mdLine) (int, bool) {
+	if len(lines) == 0 || lines[0].prefix != "" || strings.TrimRight(lines[0].rest, " \t") != "---" || lines[0].item {
+		return 0, false
+	}
+	for i := 1; i < len(lines); i++ {
+		raw := strings.TrimRight(lines[i].prefix+lines[i].rest, " \t")
+		if raw == "---" || raw == "..." {
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
+
+func isMarkdownFenceClose(rest, open string) bool {
+	t := strings.TrimRight(rest, " \t")
+	if len(t) < len(open) || strings.Trim(t, open[:1]) != "" {
+		return false
+	}
+	return true
+}
+
+func isMarkdownTableDelimiter(rest string) bool {
+	return strings.Contains(rest, "|") && mdTableDelimiterRe.MatchString(rest)
+}
+
+func (b *mdBuilder) flushRaw() {
+	if b.raw.Len() == 0 {
+		return
+	}
+	b.doc.Blocks = append(b.doc.Blocks, ast.RawBlock{Text: b.raw.String()})
+	b.raw.Reset()
+}
+
+func (b *mdBuilder) emitProse(toks []token) {
+	if len(toks) == 0 {
+		return
+	}
+	in, diags := buildSpans(toks)
+	b.flushRaw()
+	b.doc.Blocks = append(b.doc.Blocks, ast.Paragraph{In: in})
+	b.doc.Diags = append(b.doc.Diags, diags...)
+}
+
+func (b *mdBuilder) writeHeading(ln mdLine, marker string) {
+	content := ln.rest[len(marker):]
+	closing := mdATXCloseRe.FindString(content)
+	if closing == "" {
+		trimmed := strings.TrimRight(content, " \t")
+		closing = content[len(trimmed):]
+	}
+	content = content[:len(content)-len(closing)]
+
+	b.raw.WriteString(ln.prefix + marker)
+	col := len([]rune(ln.prefix+marker)) + 1
+	b.emitProse(tokenizeMarkdownInline(content, ln.num, col))
+	b.raw.WriteString(closing + ln.ending)
+}
+
+func (b *mdBuilder) flushParagraph() {
+	if len(b.para) == 0 {
+		return
+	}
+	lines := b.para
+	b.para = nil
+
+	b.raw.WriteString(lines[0].prefix)
+	var toks []token
+	tail := ""
+	for i, ln := range lines {
+		content := strings.TrimRight(ln.rest, " \t")
+		tail = ln.rest[len(content):]
+		if i+1 < len(lines) && strings.HasSuffix(content, "\\") {
+			content = strings.TrimSuffix(content, "\\")
+			tail = "\\" + tail
+		}
+		col := len([]rune(ln.prefix)) + 1
+		toks = append(toks, tokenizeMarkdownInline(content, ln.num, col)...)
+		if i+1 < len(lines) {
+			next := lines[i+1]
+			toks = append(toks, token{
+				kind:     tokenVerbatim,
+				verbatim: ast.VerbatimMarkup,
+				text:     tail + ln.ending + next.prefix,
+				pos:      ast.Pos{Line: ln.num, Col: col + len([]rune(content))},
+			})
+		} else {
+			tail += ln.ending
+		}
+	}
+	b.emitProse(toks)
+	b.raw.WriteString(tail)
+}
+
+// tokenizeMarkdownInline tokenizes prose and turns inline Markdown syntax
+// (code spans, links, autolinks, HTML, escapes) into verbatim tokens.
+func tokenizeMarkdownInline(s string, line, startCol int) []token {
+	runes := []rune(s)
+	closers := map[int]int{}
+	out := make([]token, 0, len(runes))
+	textStart := 0
+
+	emit := func(i, n int, kind ast.VerbatimKind) {
+		if i > textStart {
+			out = append(out, tokenizeInline(string(runes[textStart:i]), line, startCol+textStart)...)
+		}
+		out = append(out, token{
+			kind:     tokenVerbatim,
+			verbatim: kind,
+			text:     string(runes[i : i+n]),
+			pos:      ast.Pos{Line: line, Col: startCol + i, Off: i},
+		})
+		textStart = i + n
+	}
+
+	for i := 0; i < len(runes); {
+		if end, ok := closers[i]; ok {
+			emit(i, end-i, ast.VerbatimMarkup)
+			i = end
+			continue
+		}
+		if n, kind, ok := matchMarkdownMarkup(runes, i, closers); ok {
+			emit(i, n, kind)
+			i += n
+			continue
+		}
+		i++
+	}
+	if textStart < len(runes) {
+		out = append(out, tokenizeInline(string(runes[textStart:]), line, startCol+textStart)...)
+	}
+	return out
+}
+
+func matchMarkdownMarkup(runes []rune, i int, closers map[int]int) (int, ast.VerbatimKind, bool) {
+	r := runes[i]
+	switch r {
+	case '\\':
+		if i+1 < len(runes) && strings.ContainsRune(mdEscapable, runes[i+1]) {
+			return 2, ast.VerbatimMarkup, true
+		}
+	case '`':
+		if n, ok := matchCodeSpan(runes, i); ok {
+			return n, ast.VerbatimCode, true
+		}
+	case '<':
+		if m := mdInlineTagRe.FindString(string(runes[i:])); m != "" {
+			return len([]rune(m)), ast.VerbatimMarkup, true
+		}
+	case '!', '[':
+		open := i
+		if r == '!' {
+			if i+1 >= len(runes) || runes[i+1] != '[' {
+				return 0, 0, false
+			}
+			open = i + 1
+		}
+		closeIdx := matchingMarkdownBracket(runes, open, '[', ']')
+		if closeIdx < 0 {
+			return 0, 0, false
+		}
+		if r == '[' && open+1 < len(runes) && runes[open+1] == '^' {
+			return closeIdx - i + 1, ast.VerbatimMarkup, true
+		}
+		if closeIdx+1 >= len(runes) {
+			return 0, 0, false
+		}
+		switch runes[closeIdx+1] {
+		case '(':
+			end := matchingMarkdownBracket(runes, closeIdx+1, '(', ')')
+			if end < 0 {
+				return 0, 0, false
+			}
+			closers[closeIdx] = end + 1
+		case '[':
+			end := matchingMarkdownBracket(runes, closeIdx+1, '[', ']')
+			if end < 0 {
+				return 0, 0, false
+			}
+			closers[closeIdx] = end + 1
+		default:
+			return 0, 0, false
+		}
+		return open - i + 1, ast.VerbatimMarkup, true
+	}
+	return 0, 0, false
+}
+
+func matchingMarkdownBracket(runes []rune, open int, openCh, closeCh rune) int {
+	depth := 0
+	for j := open; j < len(runes); j++ {
+		switch runes[j] {
+		case '\\':
+			j++
+		case openCh:
+			depth++
+		case closeCh:
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
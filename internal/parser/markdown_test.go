@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+	"github.com/n0madic/txtfmt/internal/config"
+)
+
+func TestParseMarkdownKeepsRawBlocksAndSplitsProse(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.InputFormat = config.InputFormatMarkdown
+	input := "- item **one**\n  next\n\n```\ncode \"x\"\n```\n"
+	doc := Parse(input, cfg)
+
+	var raw strings.Builder
+	var paragraphs []ast.Paragraph
+	for _, blk := range doc.Blocks {
+		switch b := blk.(type) {
+		case ast.RawBlock:
+			raw.WriteString(b.Text)
+		case ast.Paragraph:
+			paragraphs = append(paragraphs, b)
+		default:
+			t.Fatalf("unexpected block %T", blk)
+		}
+	}
+	if len(paragraphs) != 1 {
+		t.Fatalf("expected one prose paragraph, got %d", len(paragraphs))
+	}
+	if !strings.Contains(raw.String(), "```\ncode \"x\"\n```\n") {
+		t.Fatalf("expected fenced code kept raw, got %q", raw.String())
+	}
+
+	var markup []string
+	strong := false
+	for _, it := range paragraphs[0].In {
+		switch v := it.(type) {
+		case ast.Verbatim:
+			if v.Kind == ast.VerbatimMarkup {
+				markup = append(markup, v.S)
+			}
+		case ast.Emphasis:
+			strong = v.Kind == ast.EmphasisStrong && v.Marker == "**"
+		}
+	}
+	if strings.Join(markup, "|") != "\n  " {
+		t.Fatalf("unexpected markup nodes: %q", markup)
+	}
+	if !strong {
+		t.Fatalf("expected strong emphasis in %#v", paragraphs[0].In)
+	}
+}
+
+func TestTokenizeMarkdownInlineLinks(t *testing.T) {
+	toks := tokenizeMarkdownInline(`see ![alt](a.png) and [text][ref] or \*x\*`, 1, 1)
+	var markup []string
+	for _, tok := range toks {
+		if tok.kind == tokenVerbatim {
+			markup = append(markup, tok.text)
+		}
+	}
+	want := []string{"![", "](a.png)", "[", "][ref]", `\*`, `\*`}
+	if strings.Join(markup, " ") != strings.Join(want, " ") {
+		t.Fatalf("expected %q, got %q", want, markup)
+	}
+}
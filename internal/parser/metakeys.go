@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+	"github.com/n0madic/txtfmt/internal/config"
+)
+
+// metaKeyAliases maps localized meta keys, lowercased, to canonical keys.
+var metaKeyAliases = map[string]string{
+	"автор": "author", "авторы": "author", "автори": "author", "author": "author", "authors": "author",
+	"название": "title", "заглавие": "title", "назва": "title", "title": "title",
+	"подзаголовок": "subtitle", "підзаголовок": "subtitle", "subtitle": "subtitle",
+	"серия": "series", "серія": "series", "цикл": "series", "series": "series",
+	"год": "year", "год издания": "year", "рік": "year", "рік видання": "year", "year": "year",
+	"перевод": "translator", "переводчик": "translator", "переклад": "translator", "перекладач": "translator",
+	"translator": "translator", "translated by": "translator", "translation": "translator",
+	"издательство": "publisher", "издатель": "publisher", "видавництво": "publisher", "видавець": "publisher", "publisher": "publisher",
+	"жанр": "genre", "genre": "genre",
+	"isbn": "isbn",
+}
+
+// metaKeyPluralRe strips the "(ы)" of "Автор(ы)" and the "(s)" of "Author(s)".
+var metaKeyPluralRe = regexp.MustCompile(`\(\p{L}{1,3}\)$`)
+
+// canonicalMetaKey returns the vocabulary key for a meta key in any case or
+// language: "АВТОР", "Автор(ы)" and "Author" are all "author".
+func canonicalMetaKey(key string) (string, bool) {
+	k := strings.ToLower(strings.Join(strings.Fields(key), " "))
+	k = strings.TrimSpace(metaKeyPluralRe.ReplaceAllString(k, ""))
+	c, ok := metaKeyAliases[k]
+	return c, ok
+}
+
+// restrictMetaLines turns meta lines the -meta-keys policy rejects back
+// into paragraphs: unknown keys with "known", lines after the first body
+// block with "header".
+func restrictMetaLines(blocks []ast.Block, mode config.MetaKeys) []ast.Block {
+	if mode != config.MetaKeysKnown && mode != config.MetaKeysHeader {
+		return blocks
+	}
+	header := true
+	for i, blk := range blocks {
+		switch b := blk.(type) {
+		case ast.MetaLineBlock:
+			if (mode == config.MetaKeysKnown && b.Canonical == "") || (mode == config.MetaKeysHeader && !header) {
+				blocks[i] = metaLineParagraph(b)
+			}
+		case ast.TitleBlock, ast.SceneBreak:
+		default:
+			header = false
+		}
+	}
+	return blocks
+}
+
+func metaLineParagraph(b ast.MetaLineBlock) ast.Paragraph {
+	in := make([]ast.Inline, 0, len(b.In)+4)
+	for i, word := range strings.Fields(b.Key) {
+		if i > 0 {
+			in = append(in, ast.Space{Kind: ast.SpaceNormal})
+		}
+		in = append(in, ast.Word{S: word})
+	}
+	in = append(in, ast.Punct{Ch: ':'})
+	if len(b.In) > 0 {
+		in = append(in, ast.Space{Kind: ast.SpaceNormal})
+		in = append(in, b.In...)
+	}
+	return ast.Paragraph{In: in}
+}
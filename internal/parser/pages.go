@@ -0,0 +1,244 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+)
+
+var (
+	bareNumberRe   = regexp.MustCompile(`^\d{1,4}$`)
+	dashedNumberRe = regexp.MustCompile(`^[-–—]\s*(\d{1,4})\s*[-–—]$`)
+)
+
+const (
+	minPageMarkers        = 3
+	minDashedPageMarkers  = 2
+	maxPageGapRatio       = 2
+	maxRunningHeaderLen   = 60
+	minRunningHeaderCount = 3
+	runningHeaderDistance = 2
+)
+
+type pageMarker struct {
+	index int
+	value int
+}
+
+// stripPageArtifacts removes page numbers (`12`, `- 12 -`) that repeat
+// through the text with growing values and a steady period, and running
+// headers that repeat next to them. A sentence split by a removed page
+// break is glued back by dropping the blank lines around it.
+func stripPageArtifacts(lines []sourceLine) ([]sourceLine, []ast.Diag) {
+	var bare, dashed []pageMarker
+	for i, line := range lines {
+		t := strings.TrimSpace(line.text)
+		if bareNumberRe.MatchString(t) {
+			n, _ := strconv.Atoi(t)
+			bare = append(bare, pageMarker{index: i, value: n})
+		} else if m := dashedNumberRe.FindStringSubmatch(t); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			dashed = append(dashed, pageMarker{index: i, value: n})
+		}
+	}
+
+	remove := make(map[int]string)
+	if len(dashed) >= minDashedPageMarkers && isPageSequence(dashed, false) {
+		for _, m := range dashed {
+			remove[m.index] = "page number"
+		}
+	}
+	if len(bare) >= minPageMarkers && isPageSequence(bare, true) {
+		headers := runningHeaderMarkers(lines, bare)
+		for _, m := range bare {
+			if headers[m.index] || breaksSentence(lines, m.index) {
+				remove[m.index] = "page number"
+			}
+		}
+	}
+	if len(remove) == 0 {
+		return lines, nil
+	}
+	markRunningHeaders(lines, remove)
+	markSplitSentences(lines, remove)
+
+	out := make([]sourceLine, 0, len(lines)-len(remove))
+	var diags []ast.Diag
+	for i, line := range lines {
+		what, ok := remove[i]
+		if !ok {
+			out = append(out, line)
+			continue
+		}
+		if what == "" {
+			continue
+		}
+		diags = append(diags, ast.Diag{
+			Pos:     ast.Pos{Line: line.line, Col: 1},
+			Code:    "PAGE_ARTIFACT_REMOVED",
+			Message: fmt.Sprintf("removed %s %q", what, strings.TrimSpace(line.text)),
+		})
+	}
+	return out, diags
+}
+
+// isPageSequence requires growing page values; bare numbers also need a
+// steady distance between them so numbered chapters are left alone.
+func isPageSequence(markers []pageMarker, steady bool) bool {
+	minGap, maxGap := -1, 0
+	for i := 1; i < len(markers); i++ {
+		if markers[i].value <= markers[i-1].value {
+			return false
+		}
+		gap := markers[i].index - markers[i-1].index
+		if minGap < 0 || gap < minGap {
+			minGap = gap
+		}
+		maxGap = max(maxGap, gap)
+	}
+	return !steady || maxGap <= minGap*maxPageGapRatio
+}
+
+// runningHeaderMarkers returns the bare numbers that have a running header
+// next to them: a short line repeated near at least three of the numbers.
+// A bare number is only a page number with such a header or when it breaks
+// a sentence in two; otherwise it numbers a section.
+func runningHeaderMarkers(lines []sourceLine, markers []pageMarker) map[int]bool {
+	isMarker := make(map[int]bool, len(markers))
+	for _, m := range markers {
+		isMarker[m.index] = true
+	}
+	near := make(map[string]map[int]bool)
+	for _, m := range markers {
+		for _, dir := range []int{-1, 1} {
+			seen := 0
+			for j := m.index + dir; j >= 0 && j < len(lines) && seen < runningHeaderDistance; j += dir {
+				t := strings.TrimSpace(lines[j].text)
+				if t == "" {
+					continue
+				}
+				seen++
+				if isMarker[j] || !looksLikeRunningHeader(t) {
+					continue
+				}
+				if near[t] == nil {
+					near[t] = make(map[int]bool)
+				}
+				near[t][m.index] = true
+			}
+		}
+	}
+	out := make(map[int]bool)
+	for _, idxs := range near {
+		if len(idxs) < minRunningHeaderCount {
+			continue
+		}
+		for idx := range idxs {
+			out[idx] = true
+		}
+	}
+	return out
+}
+
+// breaksSentence reports whether the text around line idx reads as one
+// sentence: no sentence end before it and a lowercase word after it.
+func breaksSentence(lines []sourceLine, idx int) bool {
+	before, after := "", ""
+	for j := idx - 1; j >= 0 && before == ""; j-- {
+		before = strings.TrimSpace(lines[j].text)
+	}
+	for j := idx + 1; j < len(lines) && after == ""; j++ {
+		after = strings.TrimSpace(lines[j].text)
+	}
+	if before == "" || after == "" || endsLikeSentence(before) {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(after)
+	return unicode.IsLower(r)
+}
+
+// markRunningHeaders marks short title-like lines that repeat at least three
+// times within a couple of lines of a removed page number.
+func markRunningHeaders(lines []sourceLine, remove map[int]string) {
+	near := make(map[string]map[int]bool)
+	for idx := range remove {
+		for _, dir := range []int{-1, 1} {
+			seen := 0
+			for j := idx + dir; j >= 0 && j < len(lines) && seen < runningHeaderDistance; j += dir {
+				t := strings.TrimSpace(lines[j].text)
+				if t == "" {
+					continue
+				}
+				seen++
+				if _, ok := remove[j]; ok || !looksLikeRunningHeader(t) {
+					continue
+				}
+				if near[t] == nil {
+					near[t] = make(map[int]bool)
+				}
+				near[t][j] = true
+			}
+		}
+	}
+	for _, idxs := range near {
+		if len(idxs) < minRunningHeaderCount {
+			continue
+		}
+		for j := range idxs {
+			remove[j] = "running header"
+		}
+	}
+}
+
+// markSplitSentences drops the blank lines around removed artifacts when the
+// text before them doesn't end a sentence and the text after starts
+// lowercase. Such blank lines are marked with an empty reason.
+func markSplitSentences(lines []sourceLine, remove map[int]string) {
+	for i := 0; i < len(lines); i++ {
+		if _, ok := remove[i]; !ok {
+			continue
+		}
+		start, end := i, i
+		for start > 0 && isRemovableGap(lines, remove, start-1) {
+			start--
+		}
+		for end+1 < len(lines) && isRemovableGap(lines, remove, end+1) {
+			end++
+		}
+		i = end
+		if start == 0 || end+1 >= len(lines) {
+			continue
+		}
+		before := strings.TrimSpace(lines[start-1].text)
+		after := strings.TrimSpace(lines[end+1].text)
+		r, _ := utf8.DecodeRuneInString(after)
+		if endsLikeSentence(before) || !unicode.IsLower(r) {
+			continue
+		}
+		for j := start; j <= end; j++ {
+			if _, ok := remove[j]; !ok {
+				remove[j] = ""
+			}
+		}
+	}
+}
+
+func isRemovableGap(lines []sourceLine, remove map[int]string, i int) bool {
+	if _, ok := remove[i]; ok {
+		return true
+	}
+	return strings.TrimSpace(lines[i].text) == ""
+}
+
+func looksLikeRunningHeader(t string) bool {
+	if utf8.RuneCountInString(t) > maxRunningHeaderLen {
+		return false
+	}
+	r, _ := utf8.DecodeLastRuneInString(t)
+	return !strings.ContainsRune(".,;:!?…", r)
+}
@@ -18,6 +18,10 @@ const (
 	nodeQuoteMark
 	nodeParenSpan
 	nodeQuoteSpan
+	nodeVerbatim
+	nodeEmphasisMark
+	nodeEmphasis
+	nodeFootnoteRef
 )
 
 type node struct {
@@ -25,6 +29,7 @@ type node struct {
 	text     string
 	ch       rune
 	dashKind ast.DashKind
+	verbatim ast.VerbatimKind
 	pos      ast.Pos
 
 	level ast.QuoteLevel
@@ -51,6 +56,8 @@ func buildSpans(tokens []token) ([]ast.Inline, []ast.Diag) {
 	nodes, diags := buildParenNodes(tokens)
 	nodes, quoteDiags := buildQuoteNodes(nodes)
 	diags = append(diags, quoteDiags...)
+	nodes = buildEmphasisNodes(nodes)
+	nodes = buildFootnoteRefNodes(nodes)
 	return nodesToInlines(nodes), diags
 }
 
@@ -244,6 +251,10 @@ func tokenToNode(tok token) node {
 		return node{kind: nodeEllipsis, text: tok.text, pos: tok.pos}
 	case tokenQuote:
 		return node{kind: nodeQuoteMark, ch: tok.ch, text: tok.text, pos: tok.pos}
+	case tokenVerbatim:
+		return node{kind: nodeVerbatim, text: tok.text, verbatim: tok.verbatim, pos: tok.pos}
+	case tokenEmphasis:
+		return node{kind: nodeEmphasisMark, ch: tok.ch, text: tok.text, pos: tok.pos}
 	default:
 		return node{kind: nodeWord, text: tok.text, pos: tok.pos}
 	}
@@ -262,7 +273,7 @@ func nodesToInlines(nodes []node) []ast.Inline {
 		case nodePunct:
 			out = append(out, ast.Punct{Ch: n.ch})
 		case nodeDash:
-			out = append(out, ast.Dash{Kind: n.dashKind})
+			out = append(out, ast.Dash{Kind: n.dashKind, Typewriter: len(n.text) > 1 && n.text[0] == '-'})
 		case nodeEllipsis:
 			out = append(out, ast.Ellipsis{})
 		case nodeParenSpan:
@@ -271,11 +282,96 @@ func nodesToInlines(nodes []node) []ast.Inline {
 			out = append(out, ast.QuoteSpan{Level: n.level, In: nodesToInlines(n.children)})
 		case nodeQuoteMark:
 			out = append(out, ast.Word{S: string(n.ch)})
+		case nodeVerbatim:
+			out = append(out, ast.Verbatim{Kind: n.verbatim, S: n.text})
+		case nodeEmphasisMark:
+			out = append(out, ast.Verbatim{Kind: ast.VerbatimMarkup, S: n.text})
+		case nodeEmphasis:
+			out = append(out, emphasisInline(n.text, nodesToInlines(n.children)))
+		case nodeFootnoteRef:
+			out = append(out, ast.FootnoteRef{Label: footnoteLabel(n.text), Marker: n.text})
 		}
 	}
 	return out
 }
 
+// buildEmphasisNodes pairs emphasis delimiter runs of the same marker. A run
+// opens when followed by a non-space and closes when preceded by one;
+// unmatched runs stay literal.
+func buildEmphasisNodes(nodes []node) []node {
+	for i := range nodes {
+		if nodes[i].kind == nodeParenSpan || nodes[i].kind == nodeQuoteSpan {
+			nodes[i].children = buildEmphasisNodes(nodes[i].children)
+		}
+	}
+
+	out := make([]node, 0, len(nodes))
+	openers := make([]int, 0)
+	// intraword holds the openers with a word on both sides.
+	intraword := make(map[int]bool)
+	for i, n := range nodes {
+		if n.kind != nodeEmphasisMark {
+			out = append(out, n)
+			continue
+		}
+		canClose := i > 0 && nodes[i-1].kind != nodeSpace
+		canOpen := i+1 < len(nodes) && nodes[i+1].kind != nodeSpace
+		inWord := i > 0 && i+1 < len(nodes) && nodes[i-1].kind == nodeWord && nodes[i+1].kind == nodeWord
+
+		if canClose {
+			match := -1
+			for j := len(openers) - 1; j >= 0; j-- {
+				if out[openers[j]].text == n.text {
+					match = j
+					break
+				}
+			}
+			// A mark inside a word pairs only within the word: un*frigging*believable,
+			// but not 2*3 и 4*5.
+			if match >= 0 && (inWord || intraword[openers[match]]) && hasSpaceNode(out[openers[match]+1:]) {
+				match = -1
+			}
+			if match >= 0 && openers[match] < len(out)-1 {
+				start := openers[match]
+				children := append([]node(nil), out[start+1:]...)
+				em := node{kind: nodeEmphasis, text: n.text, pos: out[start].pos, children: children}
+				out = append(out[:start], em)
+				openers = openers[:match]
+				continue
+			}
+		}
+		if canOpen {
+			openers = append(openers, len(out))
+			intraword[len(out)] = inWord
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+func hasSpaceNode(nodes []node) bool {
+	for _, n := range nodes {
+		if n.kind == nodeSpace {
+			return true
+		}
+	}
+	return false
+}
+
+func emphasisInline(marker string, in []ast.Inline) ast.Emphasis {
+	switch {
+	case marker == "~~":
+		return ast.Emphasis{Kind: ast.EmphasisStrike, Marker: marker, In: in}
+	case len(marker) == 1:
+		return ast.Emphasis{Kind: ast.EmphasisItalic, Marker: marker, In: in}
+	case len(marker) == 2:
+		return ast.Emphasis{Kind: ast.EmphasisStrong, Marker: marker, In: in}
+	default:
+		inner := ast.Emphasis{Kind: ast.EmphasisItalic, Marker: marker[2:], In: in}
+		return ast.Emphasis{Kind: ast.EmphasisStrong, Marker: marker[:2], In: []ast.Inline{inner}}
+	}
+}
+
 func isEnumeratorClose(tokens []token, idx int) bool {
 	if idx < 0 || idx >= len(tokens) || tokens[idx].kind != tokenParenClose || tokens[idx].ch != ')' {
 		return false
@@ -411,25 +507,25 @@ func shouldCloseSymmetric(nodes []node, idx int, stackLen int) bool {
 		return true
 	}
 
-	if hasSpaceBefore && !hasSpaceAfter && nextSuggestsQuoteOpen(next) {
+	if hasSpaceBefore && !hasSpaceAfter && nextSuggestsQuoteOpen(nodes, next) {
 		return false
 	}
 	if !hasSpaceBefore && hasSpaceAfter {
 		return true
 	}
 
-	if prevSuggestsQuoteClose(prev) {
-		if hasSpaceBefore && nextSuggestsQuoteOpen(next) {
+	if prevSuggestsQuoteClose(nodes, prev) {
+		if hasSpaceBefore && nextSuggestsQuoteOpen(nodes, next) {
 			return false
 		}
 		return true
 	}
 
-	if nextSuggestsQuoteClose(next) {
+	if nextSuggestsQuoteClose(nodes[next]) {
 		return true
 	}
 
-	if next.pos.Line > quote.pos.Line && prevSuggestsQuoteClose(prev) {
+	if nodes[next].pos.Line > quote.pos.Line && prevSuggestsQuoteClose(nodes, prev) {
 		return true
 	}
 
@@ -438,7 +534,7 @@ func shouldCloseSymmetric(nodes []node, idx int, stackLen int) bool {
 
 func shouldOpenSymmetric(nodes []node, idx int, stackLen int) bool {
 	next, okNext := nextSignificant(nodes, idx)
-	if !okNext || !nextSuggestsQuoteOpen(next) {
+	if !okNext || !nextSuggestsQuoteOpen(nodes, next) {
 		return false
 	}
 
@@ -454,7 +550,7 @@ func shouldOpenSymmetric(nodes []node, idx int, stackLen int) bool {
 		return false
 	}
 
-	if prevSuggestsQuoteOpen(prev) {
+	if prevSuggestsQuoteOpen(nodes, prev) {
 		return true
 	}
 
@@ -462,7 +558,7 @@ func shouldOpenSymmetric(nodes []node, idx int, stackLen int) bool {
 		return true
 	}
 
-	if prevSuggestsQuoteClose(prev) && !hasSpaceBefore {
+	if prevSuggestsQuoteClose(nodes, prev) && !hasSpaceBefore {
 		return false
 	}
 
@@ -473,30 +569,44 @@ func shouldOpenSymmetric(nodes []node, idx int, stackLen int) bool {
 	return false
 }
 
-func prevSignificant(nodes []node, idx int) (node, bool) {
+func prevSignificant(nodes []node, idx int) (int, bool) {
 	for i := idx - 1; i >= 0; i-- {
 		if nodes[i].kind == nodeSpace {
 			continue
 		}
-		return nodes[i], true
+		return i, true
 	}
-	return node{}, false
+	return 0, false
 }
 
-func nextSignificant(nodes []node, idx int) (node, bool) {
+func nextSignificant(nodes []node, idx int) (int, bool) {
 	for i := idx + 1; i < len(nodes); i++ {
 		if nodes[i].kind == nodeSpace {
 			continue
 		}
-		return nodes[i], true
+		return i, true
 	}
-	return node{}, false
+	return 0, false
+}
+
+// emphasisMarkCloses reports a mark that ends a span, `*hi*` after a word;
+// emphasisMarkOpens one that starts a span before a word.
+func emphasisMarkCloses(nodes []node, i int) bool {
+	return i > 0 && nodes[i-1].kind != nodeSpace
+}
+
+func emphasisMarkOpens(nodes []node, i int) bool {
+	return i+1 < len(nodes) && nodes[i+1].kind != nodeSpace
 }
 
-func prevSuggestsQuoteClose(n node) bool {
+func prevSuggestsQuoteClose(nodes []node, i int) bool {
+	n := nodes[i]
 	switch n.kind {
-	case nodeParenSpan, nodeQuoteSpan:
+	case nodeParenSpan, nodeQuoteSpan, nodeVerbatim:
 		return true
+	case nodeEmphasisMark:
+		// The opening mark of *"hi"* precedes an opening quote.
+		return emphasisMarkCloses(nodes, i)
 	case nodeEllipsis:
 		return true
 	case nodePunct:
@@ -517,10 +627,13 @@ func prevSuggestsQuoteClose(n node) bool {
 	return false
 }
 
-func prevSuggestsQuoteOpen(n node) bool {
+func prevSuggestsQuoteOpen(nodes []node, i int) bool {
+	n := nodes[i]
 	switch n.kind {
 	case nodeDash:
 		return true
+	case nodeEmphasisMark:
+		return !emphasisMarkCloses(nodes, i)
 	case nodePunct:
 		switch n.ch {
 		case ':', ';', ',', '(', '[', '-', '—':
@@ -550,10 +663,13 @@ func nextSuggestsQuoteClose(n node) bool {
 	return false
 }
 
-func nextSuggestsQuoteOpen(n node) bool {
+func nextSuggestsQuoteOpen(nodes []node, i int) bool {
+	n := nodes[i]
 	switch n.kind {
-	case nodeParenSpan, nodeQuoteSpan:
+	case nodeParenSpan, nodeQuoteSpan, nodeVerbatim:
 		return true
+	case nodeEmphasisMark:
+		return emphasisMarkOpens(nodes, i)
 	case nodeWord:
 		r, _ := utf8.DecodeRuneInString(n.text)
 		return unicode.IsLetter(r) || unicode.IsDigit(r)
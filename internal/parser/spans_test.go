@@ -161,3 +161,180 @@ func TestResponseLineWithInnerNameQuoteNoDiag(t *testing.T) {
 		}
 	}
 }
+
+func TestInchMarkAfterFeetIsNotQuote(t *testing.T) {
+	in, diags := parseInlineLines([]string{`"He is 5'10" tall"`}, []int{1}, false)
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %+v", diags)
+	}
+	if len(in) != 1 {
+		t.Fatalf("expected one top-level inline, got %d", len(in))
+	}
+	q, ok := in[0].(ast.QuoteSpan)
+	if !ok {
+		t.Fatalf("expected QuoteSpan, got %T", in[0])
+	}
+	found := false
+	for _, child := range q.In {
+		if w, ok := child.(ast.Word); ok && w.S == `5'10"` {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected feet-and-inches word inside quote, got %#v", q.In)
+	}
+}
+
+func TestVerbatimTokensAreKeptWhole(t *testing.T) {
+	cases := []struct {
+		line string
+		want string
+		kind ast.VerbatimKind
+	}{
+		{"see https://example.com/a-b...c, ok", "https://example.com/a-b...c", ast.VerbatimURL},
+		{"write to user@mail.ru.", "user@mail.ru", ast.VerbatimEmail},
+		{"run ./bin/run-all now", "./bin/run-all", ast.VerbatimPath},
+		{"use `a -- b...` here", "`a -- b...`", ast.VerbatimCode},
+		{"tag #go_lang", "#go_lang", ast.VerbatimTag},
+		{"ask @bob.", "@bob", ast.VerbatimMention},
+		{"(see www.test.org/x)", "www.test.org/x", ast.VerbatimURL},
+	}
+	for _, tc := range cases {
+		t.Run(tc.line, func(t *testing.T) {
+			in, _ := parseInlineLines([]string{tc.line}, []int{1}, false)
+			var found *ast.Verbatim
+			var walk func([]ast.Inline)
+			walk = func(items []ast.Inline) {
+				for _, it := range items {
+					switch v := it.(type) {
+					case ast.Verbatim:
+						found = &v
+					case ast.ParenSpan:
+						walk(v.In)
+					}
+				}
+			}
+			walk(in)
+			if found == nil {
+				t.Fatalf("expected verbatim inline in %#v", in)
+			}
+			if found.S != tc.want || found.Kind != tc.kind {
+				t.Fatalf("expected %q kind %d, got %q kind %d", tc.want, tc.kind, found.S, found.Kind)
+			}
+		})
+	}
+}
+
+func TestVerbatimNotDetectedInsideWords(t *testing.T) {
+	in, _ := parseInlineLines([]string{"C#, и/или т.е. 1/2"}, []int{1}, false)
+	for _, it := range in {
+		if v, ok := it.(ast.Verbatim); ok {
+			t.Fatalf("unexpected verbatim %q", v.S)
+		}
+	}
+}
+
+func TestEmphasisDelimitersBuildNodes(t *testing.T) {
+	cases := []struct {
+		line   string
+		kind   ast.EmphasisKind
+		marker string
+	}{
+		{"an *italic* word", ast.EmphasisItalic, "*"},
+		{"an _italic_ word", ast.EmphasisItalic, "_"},
+		{"a **strong** word", ast.EmphasisStrong, "**"},
+		{"a ~~gone~~ word", ast.EmphasisStrike, "~~"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.line, func(t *testing.T) {
+			in, _ := parseInlineLines([]string{tc.line}, []int{1}, false)
+			if len(in) != 5 {
+				t.Fatalf("expected 5 inlines, got %#v", in)
+			}
+			em, ok := in[2].(ast.Emphasis)
+			if !ok {
+				t.Fatalf("expected Emphasis, got %T", in[2])
+			}
+			if em.Kind != tc.kind || em.Marker != tc.marker {
+				t.Fatalf("unexpected emphasis %#v", em)
+			}
+		})
+	}
+}
+
+func TestQuotesInsideEmphasisStayPaired(t *testing.T) {
+	cases := map[string]int{
+		"He said *\"hi\"* to me.":   4,
+		"_\"Война и мир\"_ — роман": 0,
+	}
+	for line, at := range cases {
+		t.Run(line, func(t *testing.T) {
+			in, diags := parseInlineLines([]string{line}, []int{1}, false)
+			if len(diags) != 0 {
+				t.Fatalf("unexpected diagnostics: %+v", diags)
+			}
+			em, ok := in[at].(ast.Emphasis)
+			if !ok || len(em.In) != 1 {
+				t.Fatalf("expected emphasis around one quote, got %#v", in)
+			}
+			if _, ok := em.In[0].(ast.QuoteSpan); !ok {
+				t.Fatalf("expected QuoteSpan in emphasis, got %#v", em.In)
+			}
+		})
+	}
+}
+
+func TestIntrawordEmphasis(t *testing.T) {
+	in, _ := parseInlineLines([]string{"un*frigging*believable"}, []int{1}, false)
+	if len(in) != 3 {
+		t.Fatalf("expected word, emphasis, word, got %#v", in)
+	}
+	if _, ok := in[1].(ast.Emphasis); !ok {
+		t.Fatalf("expected Emphasis inside the word, got %#v", in[1])
+	}
+}
+
+func TestUnmatchedEmphasisStaysLiteral(t *testing.T) {
+	for _, line := range []string{"5 * 3", "snake_case", "word* here", "**open only", "Площадь 2*3 и объём 4*5 метров."} {
+		t.Run(line, func(t *testing.T) {
+			in, _ := parseInlineLines([]string{line}, []int{1}, false)
+			for _, it := range in {
+				if _, ok := it.(ast.Emphasis); ok {
+					t.Fatalf("unexpected emphasis in %#v", in)
+				}
+			}
+		})
+	}
+}
+
+func TestFootnoteRefsAttachToPrecedingWord(t *testing.T) {
+	cases := map[string]ast.FootnoteRef{
+		"слово[1] дальше": {Label: "1", Marker: "[1]"},
+		"слово*, дальше":  {Label: "*", Marker: "*"},
+		"слово² дальше":   {Label: "2", Marker: "²"},
+	}
+	for line, want := range cases {
+		t.Run(line, func(t *testing.T) {
+			in, _ := parseInlineLines([]string{line}, []int{1}, false)
+			if len(in) < 2 {
+				t.Fatalf("unexpected inlines %#v", in)
+			}
+			if got, ok := in[1].(ast.FootnoteRef); !ok || got != want {
+				t.Fatalf("expected %#v after the word, got %#v", want, in)
+			}
+		})
+	}
+}
+
+func TestFootnoteRefNotDetectedAfterSpace(t *testing.T) {
+	for _, line := range []string{"список [1] пунктов", "5 * 3", "м²"} {
+		t.Run(line, func(t *testing.T) {
+			in, _ := parseInlineLines([]string{line}, []int{1}, false)
+			for _, it := range in {
+				if _, ok := it.(ast.FootnoteRef); ok {
+					t.Fatalf("unexpected footnote ref in %#v", in)
+				}
+			}
+		})
+	}
+}
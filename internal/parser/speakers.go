@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+)
+
+// minSpeakerTurns is how many `Name:` lines a document needs before they
+// are read as a transcript rather than metadata.
+const minSpeakerTurns = 3
+
+// detectSpeakerTurns turns meta lines into speaker turns when their keys
+// repeat: an interview (`ИВАНОВ: ...`, `Q: ... / A: ...`) reuses a few
+// names, a header doesn't. A key seen once is a speaker too when its line
+// runs on from a repeating one (`ИВАНОВ:`, `ПЕТРОВ:`, `ИВАНОВ:`).
+// Vocabulary keys are always metadata, and a one-letter key that is no
+// speaker goes back to being a paragraph. A paragraph that wraps a turn onto
+// the next line continues it. blockLines holds the first source line of each
+// block.
+func detectSpeakerTurns(blocks []ast.Block, blockLines []int) []ast.Block {
+	counts := make(map[string]int)
+	for _, blk := range blocks {
+		if b, ok := blk.(ast.MetaLineBlock); ok && b.Canonical == "" {
+			counts[speakerKey(b.Key)]++
+		}
+	}
+	repeats := func(i int) bool {
+		b, ok := blocks[i].(ast.MetaLineBlock)
+		return ok && b.Canonical == "" && counts[speakerKey(b.Key)] > 1
+	}
+	adjacent := func(i, j int) bool {
+		return j >= 0 && j < len(blocks) && j < len(blockLines) && i < len(blockLines) &&
+			(blockLines[j] == blockLines[i]+1 || blockLines[j] == blockLines[i]-1)
+	}
+	turns := make([]bool, len(blocks))
+	total := 0
+	for i, blk := range blocks {
+		if b, ok := blk.(ast.MetaLineBlock); !ok || b.Canonical != "" {
+			continue
+		}
+		turns[i] = repeats(i) || adjacent(i, i-1) && repeats(i-1) || adjacent(i, i+1) && repeats(i+1)
+		if turns[i] {
+			total++
+		}
+	}
+	if total < minSpeakerTurns {
+		turns = make([]bool, len(blocks))
+	}
+
+	out := make([]ast.Block, 0, len(blocks))
+	for i, blk := range blocks {
+		switch b := blk.(type) {
+		case ast.MetaLineBlock:
+			switch {
+			case turns[i]:
+				blk = ast.SpeakerTurn{Speaker: b.Key, In: b.In}
+			case utf8.RuneCountInString(b.Key) == 1:
+				blk = metaLineParagraph(b)
+			}
+		case ast.Paragraph:
+			if len(out) == 0 {
+				break
+			}
+			turn, ok := out[len(out)-1].(ast.SpeakerTurn)
+			wrapped := i < len(blockLines) && blockLines[i] == blockLines[i-1]+1
+			if ok && wrapped {
+				in := make([]ast.Inline, 0, len(turn.In)+len(b.In)+1)
+				in = append(in, turn.In...)
+				in = append(in, ast.Space{Kind: ast.SpaceNormal})
+				turn.In = append(in, b.In...)
+				out[len(out)-1] = turn
+				continue
+			}
+		}
+		out = append(out, blk)
+	}
+	return out
+}
+
+func speakerKey(key string) string {
+	return strings.ToLower(key)
+}
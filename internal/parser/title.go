@@ -0,0 +1,227 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+)
+
+var (
+	titleSeriesRe   = regexp.MustCompile(`(?i)^(?:книга|том|серия|серія|цикл|book|volume|vol\.|series)(?:\s|$)`)
+	titleSubtitleRe = regexp.MustCompile(`(?i)^(?:роман|повесть|повість|рассказ|оповідання|сказка|казка|поэма|поема|пьеса|п'єса|a novel|novel|a story|a play)(?:[\s.,(]|$)`)
+)
+
+// titleFieldKinds maps canonical meta keys to the title page field they
+// fill.
+var titleFieldKinds = map[string]ast.TitleFieldKind{
+	"author":     ast.TitleAuthor,
+	"subtitle":   ast.TitleSubtitle,
+	"series":     ast.TitleSeries,
+	"translator": ast.TitleTranslator,
+	"year":       ast.TitleYear,
+}
+
+// titleNameStopWords are capitalized words of English titles that never
+// appear in a name.
+var titleNameStopWords = map[string]bool{
+	"the": true, "a": true, "an": true, "of": true, "and": true, "in": true,
+	"on": true, "for": true, "to": true, "at": true, "with": true,
+}
+
+// buildTitleBlock types the lines of a two-line title: an author above or
+// below the title, a subtitle such as "роман" or a series/volume line. Three
+// lines read as author, title and subtitle. Lines that can't be told apart
+// stay one title.
+func buildTitleBlock(lines []string, lineNums []int) (ast.TitleBlock, []ast.Diag) {
+	if len(lines) == 3 && isSubtitleLine(lines[2]) {
+		title, diags := buildTitleBlock(lines[:2], lineNums[:2])
+		if len(title.Fields) == 1 && title.Fields[0].Kind == ast.TitleAuthor {
+			in, subDiags := parseInlineLines(lines[2:], lineNums[2:], false)
+			title.Fields = append(title.Fields, ast.TitleField{Kind: ast.TitleSubtitle, In: in})
+			return title, append(diags, subDiags...)
+		}
+	}
+	if len(lines) != 2 {
+		in, diags := parseInlineLines(lines, lineNums, true)
+		return ast.TitleBlock{In: in}, diags
+	}
+
+	first, second := lines[0], lines[1]
+	titleIdx := 0
+	var kind ast.TitleFieldKind
+	switch {
+	case isTitleSeriesLine(second):
+		kind = ast.TitleSeries
+	case isTitleSeriesLine(first):
+		titleIdx, kind = 1, ast.TitleSeries
+	case isSubtitleLine(second):
+		kind = ast.TitleSubtitle
+	case looksLikeAuthorName(first) && !looksLikeAuthorName(second):
+		titleIdx, kind = 1, ast.TitleAuthor
+	case looksLikeAuthorName(second) && !looksLikeAuthorName(first):
+		kind = ast.TitleAuthor
+	case capsAuthorLine(first, second) == 0:
+		titleIdx, kind = 1, ast.TitleAuthor
+	case capsAuthorLine(first, second) == 1:
+		kind = ast.TitleAuthor
+	default:
+		in, diags := parseInlineLines(lines, lineNums, true)
+		return ast.TitleBlock{In: in}, diags
+	}
+
+	other := 1 - titleIdx
+	in, diags := parseInlineLines(lines[titleIdx:titleIdx+1], lineNums[titleIdx:titleIdx+1], false)
+	fieldIn, fieldDiags := parseInlineLines(lines[other:other+1], lineNums[other:other+1], false)
+	title := ast.TitleBlock{
+		In:     in,
+		Fields: []ast.TitleField{{Kind: kind, Before: other < titleIdx, In: fieldIn}},
+	}
+	return title, append(diags, fieldDiags...)
+}
+
+// absorbTitleMeta moves `Автор:`, `Перевод:` and similar lines that follow
+// the title into its fields. It stops at the first other meta line, so the
+// lines after it keep their order.
+func absorbTitleMeta(blocks []ast.Block) []ast.Block {
+	if len(blocks) == 0 {
+		return blocks
+	}
+	title, ok := blocks[0].(ast.TitleBlock)
+	if !ok {
+		return blocks
+	}
+	i := 1
+	for ; i < len(blocks); i++ {
+		meta, ok := blocks[i].(ast.MetaLineBlock)
+		if !ok {
+			break
+		}
+		kind, ok := titleFieldKindForKey(meta.Key)
+		if !ok {
+			break
+		}
+		title.Fields = append(title.Fields, ast.TitleField{Kind: kind, Key: meta.Key, In: meta.In})
+	}
+	out := make([]ast.Block, 0, len(blocks)-i+1)
+	out = append(out, title)
+	return append(out, blocks[i:]...)
+}
+
+func titleFieldKindForKey(key string) (ast.TitleFieldKind, bool) {
+	canonical, _ := canonicalMetaKey(key)
+	kind, ok := titleFieldKinds[canonical]
+	return kind, ok
+}
+
+func isTitleSeriesLine(line string) bool {
+	return titleSeriesRe.MatchString(normalizeStructureLine(line))
+}
+
+// standsAsTitle accepts a title page that no front matter follows: its lines
+// must have been told apart into fields, carry no end punctuation and start
+// with capitals, but for the subtitle.
+func standsAsTitle(title ast.TitleBlock, lines []string) bool {
+	if len(title.Fields) == 0 {
+		return false
+	}
+	for _, line := range lines {
+		if endsWithClausePunct(line) || !isSubtitleLine(line) && !startsCapitalized(line) {
+			return false
+		}
+	}
+	return true
+}
+
+func endsWithClausePunct(line string) bool {
+	t := normalizeStructureLine(line)
+	return t != "" && strings.ContainsAny(t[len(t)-1:], ",;:.")
+}
+
+func isSubtitleLine(line string) bool {
+	t := normalizeStructureLine(line)
+	if strings.HasPrefix(t, "(") && strings.HasSuffix(t, ")") {
+		return true
+	}
+	return titleSubtitleRe.MatchString(t)
+}
+
+// looksLikeAuthorName accepts two to four capitalized words or initials:
+// "Лев Толстой", "Л. Н. Толстой", "J. R. R. Tolkien", "М. Салтыков-Щедрин".
+// Words in capitals are a title: "ВОЙНА И МИР".
+func looksLikeAuthorName(line string) bool {
+	return authorNameShape(line, false)
+}
+
+// capsAuthorLine picks the author when capitals hide the case of a name:
+// the only line that reads as a name once capitals are allowed ("ЛЕВ
+// ТОЛСТОЙ" over "ВОЙНА И МИР"), or the shorter of two such lines set all in
+// capitals ("CHARLES DICKENS" over "GREAT EXPECTATIONS"). It returns -1 when
+// the lines can't be told apart.
+func capsAuthorLine(first, second string) int {
+	firstName, secondName := authorNameShape(first, true), authorNameShape(second, true)
+	switch {
+	case firstName && !secondName:
+		return 0
+	case secondName && !firstName:
+		return 1
+	case firstName && isCapsLine(first) && isCapsLine(second):
+		if utf8.RuneCountInString(first) <= utf8.RuneCountInString(second) {
+			return 0
+		}
+		return 1
+	}
+	return -1
+}
+
+func isCapsLine(line string) bool {
+	t := normalizeStructureLine(line)
+	return strings.ToUpper(t) == t
+}
+
+// authorNameShape checks the words of a name; caps allows words set in
+// capitals.
+func authorNameShape(line string, caps bool) bool {
+	t := normalizeStructureLine(line)
+	words := strings.Fields(t)
+	if len(words) < 2 || len(words) > 4 || utf8.RuneCountInString(t) > 40 {
+		return false
+	}
+	for _, w := range words {
+		if titleNameStopWords[strings.ToLower(w)] {
+			return false
+		}
+		if isInitials(w) {
+			continue
+		}
+		r, _ := utf8.DecodeRuneInString(w)
+		if !unicode.IsUpper(r) || utf8.RuneCountInString(w) == 1 {
+			return false
+		}
+		if !caps && strings.ToUpper(w) == w {
+			return false
+		}
+		for _, c := range w {
+			if !unicode.IsLetter(c) && c != '-' && c != '\'' && c != '’' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isInitials matches "Л.", "Л.Н." and "J.R.R.".
+func isInitials(w string) bool {
+	if !strings.HasSuffix(w, ".") {
+		return false
+	}
+	for _, part := range strings.Split(strings.TrimSuffix(w, "."), ".") {
+		r, size := utf8.DecodeRuneInString(part)
+		if size != len(part) || !unicode.IsUpper(r) {
+			return false
+		}
+	}
+	return true
+}
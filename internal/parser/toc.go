@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+	"github.com/n0madic/txtfmt/internal/config"
+)
+
+// contentsTitles is the heading of a generated table of contents.
+var contentsTitles = map[config.Lang]string{
+	config.LangRU: "Содержание",
+	config.LangUA: "Зміст",
+	config.LangEN: "Contents",
+}
+
+// generateContents builds a contents block from the headings of a document
+// that has none and puts it after the title page.
+func generateContents(blocks []ast.Block, lang config.Lang) []ast.Block {
+	var entries []ast.ContentsEntry
+	for _, blk := range blocks {
+		switch b := blk.(type) {
+		case ast.ContentsBlock:
+			return blocks
+		case ast.Heading:
+			entries = append(entries, ast.ContentsEntry{Level: b.Level, In: append([]ast.Inline(nil), b.In...)})
+		}
+	}
+	if len(entries) == 0 {
+		return blocks
+	}
+
+	at := 0
+	for at < len(blocks) {
+		switch blocks[at].(type) {
+		case ast.TitleBlock, ast.MetaLineBlock:
+			at++
+			continue
+		}
+		break
+	}
+	contents := ast.ContentsBlock{In: []ast.Inline{ast.Word{S: contentsTitles[lang]}}, Entries: entries}
+	out := make([]ast.Block, 0, len(blocks)+1)
+	out = append(out, blocks[:at]...)
+	out = append(out, contents)
+	return append(out, blocks[at:]...)
+}
+
+// checkContents reports contents entries that don't match the headings of
+// the document: headings with no entry, entries with no heading and entries
+// worded differently from the chapter they name. entryLines are the source
+// lines of the first contents block's entries.
+func checkContents(blocks []ast.Block, blockLines, entryLines []int) []ast.Diag {
+	type item struct {
+		text string
+		line int
+		used bool
+	}
+	var entries, headings []*item
+	contentsLine := 0
+	for i, blk := range blocks {
+		switch b := blk.(type) {
+		case ast.ContentsBlock:
+			if entries != nil {
+				continue
+			}
+			contentsLine = blockLines[i]
+			entries = make([]*item, 0, len(b.Entries))
+			for k, e := range b.Entries {
+				line := blockLines[i]
+				if k < len(entryLines) {
+					line = entryLines[k]
+				}
+				entries = append(entries, &item{text: contentsText(e.In), line: line})
+			}
+		case ast.Heading:
+			headings = append(headings, &item{text: contentsText(b.In), line: blockLines[i]})
+		}
+	}
+	if contentsLine == 0 {
+		return nil
+	}
+
+	var diags []ast.Diag
+	report := func(line int, code, msg string) {
+		diags = append(diags, ast.Diag{Pos: ast.Pos{Line: line, Col: 1}, Code: code, Message: msg})
+	}
+	match := func(same func(e, h *item) bool, onMatch func(e, h *item)) {
+		for _, e := range entries {
+			for _, h := range headings {
+				if e.used || h.used || !same(e, h) {
+					continue
+				}
+				e.used, h.used = true, true
+				if onMatch != nil {
+					onMatch(e, h)
+				}
+			}
+		}
+	}
+	match(func(e, h *item) bool { return strings.EqualFold(e.text, h.text) }, nil)
+	match(func(e, h *item) bool {
+		label := contentsLabel(e.text)
+		return label != "" && label == contentsLabel(h.text)
+	}, func(e, h *item) {
+		report(h.line, "TOC_MISMATCH", fmt.Sprintf("contents entry %q differs from heading %q", e.text, h.text))
+	})
+	for _, e := range entries {
+		if !e.used {
+			report(e.line, "TOC_EXTRA", fmt.Sprintf("contents entry %q has no heading", e.text))
+		}
+	}
+	for _, h := range headings {
+		if !h.used {
+			report(h.line, "TOC_MISSING", fmt.Sprintf("heading %q is missing from contents", h.text))
+		}
+	}
+	return diags
+}
+
+// contentsText is the words of an entry or heading without punctuation:
+// "Глава II. Развязка" and "Глава II Развязка" read the same.
+func contentsText(in []ast.Inline) string {
+	var words []string
+	var walk func([]ast.Inline)
+	walk = func(in []ast.Inline) {
+		for _, n := range in {
+			switch v := n.(type) {
+			case ast.Word:
+				words = append(words, v.S)
+			case ast.Verbatim:
+				words = append(words, v.S)
+			case ast.QuoteSpan:
+				walk(v.In)
+			case ast.ParenSpan:
+				walk(v.In)
+			case ast.Emphasis:
+				walk(v.In)
+			}
+		}
+	}
+	walk(in)
+	return strings.Join(words, " ")
+}
+
+// contentsLabel is the chapter keyword and number, "глава ii", that pairs a
+// reworded entry with its heading.
+func contentsLabel(text string) string {
+	m := bookHeadingRe.FindStringSubmatch(text)
+	if m == nil {
+		return ""
+	}
+	number, _, _ := strings.Cut(m[2], " ")
+	return strings.ToLower(m[1] + " " + number)
+}
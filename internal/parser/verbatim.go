@@ -0,0 +1,130 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+)
+
+var (
+	urlRe          = regexp.MustCompile(`^(?:(?:https?|ftp)://|www\.)\S+`)
+	emailRe        = regexp.MustCompile(`^[\p{L}\p{N}._%+-]+@[\p{L}\p{N}-]+(?:\.[\p{L}\p{N}-]+)+`)
+	mentionRe      = regexp.MustCompile(`^@[\p{L}\p{N}_]+`)
+	hashtagRe      = regexp.MustCompile(`^#[\p{L}_][\p{L}\p{N}_]*`)
+	absPathRe      = regexp.MustCompile(`^(?:~|\.{1,2})?/[\p{L}\p{N}._-]+(?:/[\p{L}\p{N}._-]*)*`)
+	relPathRe      = regexp.MustCompile(`^[\p{L}\p{N}_.-]+(?:/[\p{L}\p{N}_.-]+)+\.[A-Za-z0-9]{1,5}`)
+	windowsPathRe  = regexp.MustCompile(`^[A-Za-z]:\\\S+`)
+	bareDomainRe   = regexp.MustCompile(`^[\p{L}\p{N}-]+(?:\.[\p{L}\p{N}-]+)*\.(?:com|org|net|io|dev|info|edu|gov|ru|ua|рф|укр|uk|de|eu)(?:/\S*)?`)
+	verbatimTailRe = regexp.MustCompile(`[.,;:!?…»"”’']+$`)
+)
+
+// matchVerbatim recognizes text that typography must not touch: code spans,
+// URLs, e-mail addresses, file paths, hashtags and mentions. It returns the
+// kind and the number of runes consumed.
+func matchVerbatim(runes []rune, i int) (ast.VerbatimKind, int, bool) {
+	if runes[i] == '`' {
+		if n, ok := matchCodeSpan(runes, i); ok {
+			return ast.VerbatimCode, n, true
+		}
+		return 0, 0, false
+	}
+	if !isVerbatimBoundary(runes, i) {
+		return 0, 0, false
+	}
+
+	end := i
+	for end < len(runes) && !unicode.IsSpace(runes[end]) {
+		end++
+	}
+	chunk := string(runes[i:end])
+
+	if m := urlRe.FindString(chunk); m != "" {
+		return ast.VerbatimURL, trimmedVerbatimLen(m), true
+	}
+	if m := emailRe.FindString(chunk); m != "" {
+		return ast.VerbatimEmail, len([]rune(m)), true
+	}
+	if m := mentionRe.FindString(chunk); m != "" {
+		return ast.VerbatimMention, len([]rune(m)), true
+	}
+	if m := hashtagRe.FindString(chunk); m != "" {
+		return ast.VerbatimTag, len([]rune(m)), true
+	}
+	if m := windowsPathRe.FindString(chunk); m != "" {
+		return ast.VerbatimPath, trimmedVerbatimLen(m), true
+	}
+	if m := absPathRe.FindString(chunk); m != "" && isPathLike(m) {
+		return ast.VerbatimPath, trimmedVerbatimLen(m), true
+	}
+	if m := relPathRe.FindString(chunk); m != "" {
+		return ast.VerbatimPath, len([]rune(m)), true
+	}
+	if m := bareDomainRe.FindString(chunk); m != "" {
+		return ast.VerbatimURL, trimmedVerbatimLen(m), true
+	}
+	return 0, 0, false
+}
+
+func matchCodeSpan(runes []rune, i int) (int, bool) {
+	open := 0
+	for i+open < len(runes) && runes[i+open] == '`' {
+		open++
+	}
+	for j := i + open; j < len(runes); j++ {
+		if runes[j] != '`' {
+			continue
+		}
+		run := 0
+		for j+run < len(runes) && runes[j+run] == '`' {
+			run++
+		}
+		if run == open && j > i+open {
+			return j + run - i, true
+		}
+		j += run - 1
+	}
+	return 0, false
+}
+
+func isVerbatimBoundary(runes []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev := runes[i-1]
+	if unicode.IsSpace(prev) {
+		return true
+	}
+	switch prev {
+	case '(', '[', '{', '<', '«', '"', '“', '„', '‘', '\'':
+		return true
+	default:
+		return false
+	}
+}
+
+func isPathLike(s string) bool {
+	if strings.HasPrefix(s, "~/") || strings.HasPrefix(s, "./") || strings.HasPrefix(s, "../") {
+		return true
+	}
+	return strings.Count(s, "/") >= 2
+}
+
+// trimmedVerbatimLen drops trailing sentence punctuation and unbalanced
+// closing brackets that belong to the surrounding text.
+func trimmedVerbatimLen(s string) int {
+	for {
+		trimmed := verbatimTailRe.ReplaceAllString(s, "")
+		switch {
+		case strings.HasSuffix(trimmed, ")") && strings.Count(trimmed, "(") < strings.Count(trimmed, ")"):
+			trimmed = strings.TrimSuffix(trimmed, ")")
+		case strings.HasSuffix(trimmed, "]") && strings.Count(trimmed, "[") < strings.Count(trimmed, "]"):
+			trimmed = strings.TrimSuffix(trimmed, "]")
+		}
+		if trimmed == s {
+			return len([]rune(s))
+		}
+		s = trimmed
+	}
+}
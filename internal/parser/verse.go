@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+)
+
+const (
+	maxVerseLineLen  = 60
+	minVerseLines    = 3
+	maxVerseLenRatio = 2.5
+)
+
+// parseVerseStanza detects a stanza: short lines of similar length that all
+// start with a capital letter or follow a ladder of indents, or lines that end
+// with an explicit hard break (two trailing spaces or `\`). afterVerse relaxes
+// the line count for the next stanza of the same poem.
+func parseVerseStanza(c candidate, afterVerse bool) (ast.Stanza, []ast.Diag, bool) {
+	if !looksLikeVerse(c.lines, afterVerse) {
+		return ast.Stanza{}, nil, false
+	}
+
+	minIndent := -1
+	for _, line := range c.lines {
+		trimmed, _ := trimLeftWithCol(line)
+		indent := indentWidth(line[:len(line)-len(trimmed)])
+		if minIndent < 0 || indent < minIndent {
+			minIndent = indent
+		}
+	}
+
+	var stanza ast.Stanza
+	var diags []ast.Diag
+	for i, line := range c.lines {
+		trimmed, col := trimLeftWithCol(line)
+		indent := indentWidth(line[:len(line)-len(trimmed)])
+		text := strings.TrimRight(strings.TrimRightFunc(trimmed, unicode.IsSpace), "\\")
+		in, inDiags := parseInlineLinesWithCols([]string{strings.TrimRightFunc(text, unicode.IsSpace)}, []int{c.lineNums[i]}, []int{col}, false)
+		stanza.Lines = append(stanza.Lines, ast.VerseLine{Indent: indent - minIndent, In: in})
+		diags = append(diags, inDiags...)
+	}
+	return stanza, diags, true
+}
+
+func looksLikeVerse(lines []string, afterVerse bool) bool {
+	if len(lines) < 2 {
+		return false
+	}
+
+	hardBreaks := 0
+	capitalized := 0
+	indented := 0
+	sentenceEnds := 0
+	shortest, longest := -1, 0
+	for i, line := range lines {
+		if isDialogueLine(line) {
+			return false
+		}
+		if _, ok := parseListItemLine(line); ok {
+			return false
+		}
+		if i+1 < len(lines) && (strings.HasSuffix(line, "  ") || strings.HasSuffix(line, "\\")) {
+			hardBreaks++
+		}
+		text := strings.TrimSpace(line)
+		n := utf8.RuneCountInString(text)
+		if n > maxVerseLineLen {
+			return false
+		}
+		if shortest < 0 || n < shortest {
+			shortest = n
+		}
+		longest = max(longest, n)
+		if startsCapitalized(text) {
+			capitalized++
+		}
+		if hasLeadingIndent(line) {
+			indented++
+		}
+		if endsLikeSentence(text) {
+			sentenceEnds++
+		}
+	}
+
+	if hardBreaks == len(lines)-1 {
+		return true
+	}
+	if len(lines) < minVerseLines && !afterVerse {
+		return false
+	}
+	if float64(longest) > float64(shortest)*maxVerseLenRatio || sentenceEnds == len(lines) {
+		return false
+	}
+	// A ladder of indents, not just the first line of a paragraph.
+	ladder := indented >= 2 && indented < len(lines)
+	return capitalized == len(lines) || ladder
+}
+
+func startsCapitalized(s string) bool {
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			return unicode.IsUpper(r)
+		}
+		if unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package printer
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+	"github.com/n0madic/txtfmt/internal/config"
+)
+
+// translit spells Cyrillic in Latin letters for heading ids, after the
+// Russian passport rules; ukTranslit holds the letters Ukrainian reads
+// differently.
+var (
+	translit = map[rune]string{
+		'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+		'ж': "zh", 'з': "z", 'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m",
+		'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+		'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+		'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "iu", 'я': "ia",
+		'є': "ie", 'і': "i", 'ї': "i", 'ґ': "g",
+	}
+	ukTranslit = map[rune]string{'г': "h", 'и': "y"}
+)
+
+// headingAnchors holds the ids of the headings of a document and hands them
+// out to the contents entries with the same text.
+type headingAnchors struct {
+	ids    map[int]string
+	byText map[string][]string
+}
+
+func collectHeadingAnchors(doc ast.Document) *headingAnchors {
+	a := &headingAnchors{ids: make(map[int]string), byText: make(map[string][]string)}
+	used := make(map[string]bool)
+	for i, blk := range doc.Blocks {
+		h, ok := blk.(ast.Heading)
+		if !ok {
+			continue
+		}
+		base := slugify(printInlines(h.In, doc.Style), doc.Lang)
+		id := base
+		// "Глава 1" twice and "Глава 1-2" would both get glava-1-2.
+		for n := 2; used[id]; n++ {
+			id = base + "-" + strconv.Itoa(n)
+		}
+		used[id] = true
+		a.ids[i] = id
+		a.byText[base] = append(a.byText[base], id)
+	}
+	return a
+}
+
+// link returns the id of the next heading that reads like the entry, or ""
+// when there is none.
+func (a *headingAnchors) link(entry ast.ContentsEntry, style config.Style, lang config.Lang) string {
+	if a == nil {
+		return ""
+	}
+	base := slugify(printInlines(entry.In, style), lang)
+	ids := a.byText[base]
+	if len(ids) == 0 {
+		return ""
+	}
+	a.byText[base] = ids[1:]
+	return ids[0]
+}
+
+// slugify makes a stable id from heading text: "Глава 1. Начало" becomes
+// "glava-1-nachalo".
+func slugify(s string, lang config.Lang) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		var part string
+		if lang == config.LangUA && ukTranslit[r] != "" {
+			part = ukTranslit[r]
+		} else if t, ok := translit[r]; ok {
+			part = t
+		} else if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			part = string(r)
+		} else {
+			dash = b.Len() > 0
+			continue
+		}
+		if part == "" {
+			continue
+		}
+		if dash {
+			b.WriteByte('-')
+			dash = false
+		}
+		b.WriteString(part)
+	}
+	if b.Len() == 0 {
+		return "section"
+	}
+	return b.String()
+}
@@ -0,0 +1,124 @@
+package printer
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+	"github.com/n0madic/txtfmt/internal/config"
+)
+
+func printListBlock(b ast.ListBlock, style config.Style) string {
+	lines := make([]string, 0, len(b.Items))
+	for _, item := range b.Items {
+		indent := strings.Repeat("  ", max(item.Level-1, 0))
+		lines = append(lines, indent+item.Marker+" "+printInlines(item.In, style))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// printMarkdownListBlock indents nested items to the content column of their
+// parent so CommonMark keeps the nesting; letter markers become numbers.
+func printMarkdownListBlock(b ast.ListBlock, style config.Style) string {
+	lines := make([]string, 0, len(b.Items))
+	offsets := []int{0}
+	counters := []int{0}
+	for _, item := range b.Items {
+		level := min(max(item.Level, 1), len(offsets))
+		offsets = offsets[:level]
+		counters = counters[:level]
+		counters[level-1]++
+
+		marker := markdownListMarker(item, counters[level-1])
+		lines = append(lines, strings.Repeat(" ", offsets[level-1])+marker+" "+printMarkdownInlines(item.In, style))
+		offsets = append(offsets, offsets[level-1]+utf8.RuneCountInString(marker)+1)
+		counters = append(counters, 0)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func markdownListMarker(item ast.ListItem, n int) string {
+	if item.Kind == ast.ListUnordered {
+		return "-"
+	}
+	if _, ok := listMarkerNumber(item.Marker); ok {
+		return item.Marker
+	}
+	return strconv.Itoa(n) + "."
+}
+
+func listMarkerNumber(marker string) (int, bool) {
+	if marker == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(marker[:len(marker)-1])
+	return n, err == nil
+}
+
+type listTreeNode struct {
+	Item     ast.ListItem
+	Children []*listTreeNode
+}
+
+func buildListTree(items []ast.ListItem) []*listTreeNode {
+	root := &listTreeNode{}
+	stack := []*listTreeNode{root}
+	for _, item := range items {
+		lvl := max(item.Level, 1)
+		for len(stack)-1 >= lvl {
+			stack = stack[:len(stack)-1]
+		}
+		node := &listTreeNode{Item: item}
+		parent := stack[len(stack)-1]
+		parent.Children = append(parent.Children, node)
+		stack = append(stack, node)
+	}
+	return root.Children
+}
+
+func printHTMLListBlock(b ast.ListBlock, style config.Style) []string {
+	return renderListTreeHTML(buildListTree(b.Items), "  ", style)
+}
+
+func renderListTreeHTML(nodes []*listTreeNode, indent string, style config.Style) []string {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	first := nodes[0].Item
+	tag := "ul"
+	attrs := ""
+	if first.Kind == ast.ListOrdered {
+		tag = "ol"
+		r, _ := utf8.DecodeRuneInString(first.Marker)
+		if unicode.IsLetter(r) {
+			attrs = " type=\"a\""
+		} else if n, ok := listMarkerNumber(first.Marker); ok && n != 1 {
+			attrs = " start=\"" + strconv.Itoa(n) + "\""
+		}
+	}
+
+	lines := make([]string, 0, len(nodes)*3+2)
+	lines = append(lines, indent+"<"+tag+attrs+">")
+	for _, node := range nodes {
+		text := printHTMLInlines(node.Item.In, style)
+		if len(node.Children) == 0 {
+			lines = append(lines, indent+"  <li>"+text+"</li>")
+			continue
+		}
+		lines = append(lines, indent+"  <li>"+text)
+		lines = append(lines, renderListTreeHTML(node.Children, indent+"    ", style)...)
+		lines = append(lines, indent+"  </li>")
+	}
+	lines = append(lines, indent+"</"+tag+">")
+	return lines
+}
+
+func listKindName(k ast.ListKind) string {
+	if k == ast.ListOrdered {
+		return "ordered"
+	}
+	return "unordered"
+}
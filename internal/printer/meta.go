@@ -0,0 +1,110 @@
+package printer
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+)
+
+// documentMeta returns the front matter of the document. With
+// opts.FrontMatter the leading title and `Key: value` lines join it; rest is
+// what's left of the blocks once they are moved out.
+func documentMeta(doc ast.Document, opts Options) (fields []ast.MetaField, rest []ast.Block) {
+	fields = append(fields, doc.Meta...)
+	rest = doc.Blocks
+	if !opts.FrontMatter {
+		return fields, rest
+	}
+	for len(rest) > 0 {
+		switch b := rest[0].(type) {
+		case ast.TitleBlock:
+			fields = append(fields, ast.MetaField{Key: "title", Value: printInlines(b.In, doc.Style)})
+			for _, f := range b.Fields {
+				fields = append(fields, ast.MetaField{Key: titleFieldName(f.Kind), Value: printInlines(f.In, doc.Style)})
+			}
+		case ast.MetaLineBlock:
+			key := b.Key
+			if b.Canonical != "" {
+				key = b.Canonical
+			}
+			fields = append(fields, ast.MetaField{Key: key, Value: printInlines(b.In, doc.Style)})
+		default:
+			return fields, rest
+		}
+		rest = rest[1:]
+	}
+	return fields, rest
+}
+
+func metaValue(f ast.MetaField) string {
+	if len(f.Items) > 0 {
+		return strings.Join(f.Items, ", ")
+	}
+	return f.Value
+}
+
+func printYAMLFrontMatter(fields []ast.MetaField) string {
+	lines := make([]string, 0, len(fields)+2)
+	lines = append(lines, "---")
+	for _, f := range fields {
+		key := yamlScalar(f.Key)
+		if len(f.Items) == 0 {
+			lines = append(lines, key+": "+yamlScalar(f.Value))
+			continue
+		}
+		lines = append(lines, key+":")
+		for _, item := range f.Items {
+			lines = append(lines, "  - "+yamlScalar(item))
+		}
+	}
+	lines = append(lines, "---")
+	return strings.Join(lines, "\n")
+}
+
+// yamlScalar quotes values YAML would read as something else: empty
+// strings, booleans and numbers, or text with indicator characters.
+func yamlScalar(s string) string {
+	if s == "" || s != strings.TrimSpace(s) || strings.ContainsAny(s, "\n\t\"\\") ||
+		strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") ||
+		strings.ContainsRune("-?:,[]{}#&*!|>'%@`", rune(s[0])) {
+		return strconv.Quote(s)
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return strconv.Quote(s)
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// printHTMLMeta writes the metadata as microdata of the article: a
+// `<meta itemprop>` is valid in body content, `<meta name>` is not.
+func printHTMLMeta(fields []ast.MetaField) []string {
+	lines := make([]string, 0, len(fields))
+	for _, f := range fields {
+		prop := strings.Join(strings.Fields(f.Key), "-")
+		lines = append(lines, "  <meta itemprop=\""+escapeHTMLText(prop)+"\" content=\""+escapeHTMLText(metaValue(f))+"\" />")
+	}
+	return lines
+}
+
+func printXMLMeta(fields []ast.MetaField) []string {
+	lines := make([]string, 0, len(fields)+2)
+	lines = append(lines, "  <metadata>")
+	for _, f := range fields {
+		if len(f.Items) == 0 {
+			lines = append(lines, "    <field name=\""+escapeXMLAttr(f.Key)+"\">"+escapeXMLText(f.Value)+"</field>")
+			continue
+		}
+		lines = append(lines, "    <field name=\""+escapeXMLAttr(f.Key)+"\">")
+		for _, item := range f.Items {
+			lines = append(lines, "      <item>"+escapeXMLText(item)+"</item>")
+		}
+		lines = append(lines, "    </field>")
+	}
+	lines = append(lines, "  </metadata>")
+	return lines
+}
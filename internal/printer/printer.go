@@ -3,7 +3,9 @@ package printer
 import (
 	"fmt"
 	"html"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/n0madic/txtfmt/internal/ast"
 	"github.com/n0madic/txtfmt/internal/config"
@@ -32,37 +34,100 @@ func ParseFormat(raw string) (Format, error) {
 	}
 }
 
+func ParseContentsPages(raw string) (string, error) {
+	p := strings.ToLower(strings.TrimSpace(raw))
+	switch p {
+	case "":
+		return ContentsPagesKeep, nil
+	case ContentsPagesKeep, ContentsPagesOmit:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unsupported -contents-pages value %q (expected keep|omit)", raw)
+	}
+}
+
+// Options tune output that doesn't depend on the format.
+type Options struct {
+	// SceneBreak is the marker printed for every scene break, the format's
+	// own marker when empty, or SceneBreakKeep to print the source marker.
+	SceneBreak string
+	// SceneBreakClass is the HTML class of scene breaks.
+	SceneBreakClass string
+	// FrontMatter moves the leading title and meta lines into the
+	// document metadata.
+	FrontMatter bool
+	// ContentsPages is ContentsPagesOmit to drop the page numbers of
+	// contents entries, e.g. for e-books.
+	ContentsPages string
+	// HTMLSections wraps every heading and the text under it in <section>.
+	HTMLSections bool
+}
+
+const (
+	SceneBreakKeep    = "keep"
+	ContentsPagesKeep = "keep"
+	ContentsPagesOmit = "omit"
+)
+
+// contentsWidth is the width plain contents lines are right-aligned to.
+const contentsWidth = 60
+
 func Print(doc ast.Document) string {
 	return PrintWithFormat(doc, FormatPlain)
 }
 
 func PrintWithFormat(doc ast.Document, format Format) string {
+	return PrintWithOptions(doc, format, Options{})
+}
+
+func PrintWithOptions(doc ast.Document, format Format, opts Options) string {
+	if doc.Input == config.InputFormatMarkdown {
+		return printSource(doc)
+	}
 	switch format {
 	case FormatMarkdown:
-		return printMarkdown(doc)
+		return printMarkdown(doc, opts)
 	case FormatHTML:
-		return printHTML(doc)
+		return printHTML(doc, opts)
 	case FormatXML:
-		return printXML(doc)
+		return printXML(doc, opts)
 	case FormatPlain:
 		fallthrough
 	default:
-		return printPlain(doc)
+		return printPlain(doc, opts)
+	}
+}
+
+// printSource writes blocks back without separators; used for Markdown input
+// where raw blocks already carry the original syntax and line breaks.
+func printSource(doc ast.Document) string {
+	var b strings.Builder
+	for _, blk := range doc.Blocks {
+		switch bl := blk.(type) {
+		case ast.RawBlock:
+			b.WriteString(bl.Text)
+		case ast.Paragraph:
+			b.WriteString(printInlines(bl.In, doc.Style))
+		}
 	}
+	return b.String()
 }
 
-func printPlain(doc ast.Document) string {
-	parts := make([]string, 0, len(doc.Blocks))
+func printPlain(doc ast.Document, opts Options) string {
+	parts := make([]string, 0, len(doc.Blocks)+1)
+	if len(doc.Meta) > 0 {
+		parts = append(parts, printYAMLFrontMatter(doc.Meta))
+	}
 	for _, blk := range doc.Blocks {
 		switch b := blk.(type) {
 		case ast.TitleBlock:
-			parts = append(parts, printInlines(b.In, doc.Style))
+			parts = append(parts, printTitleBlock(b, doc.Style)...)
 		case ast.Paragraph:
 			parts = append(parts, printInlines(b.In, doc.Style))
 		case ast.Heading:
 			parts = append(parts, strings.Repeat("#", b.Level)+" "+printInlines(b.In, doc.Style))
 		case ast.ContentsBlock:
-			parts = append(parts, printContentsBlock(b, doc.Style))
+			parts = append(parts, printContentsBlock(b, doc.Style, opts))
 		case ast.MetaLineBlock:
 			value := printInlines(b.In, doc.Style)
 			if value == "" {
@@ -70,148 +135,287 @@ func printPlain(doc ast.Document) string {
 			} else {
 				parts = append(parts, b.Key+": "+value)
 			}
+		case ast.SpeakerTurn:
+			parts = append(parts, b.Speaker+": "+printInlines(b.In, doc.Style))
 		case ast.DialogueBlock:
 			lines := make([]string, 0, len(b.Turns))
 			for _, turn := range b.Turns {
 				lines = append(lines, printInlines(turn.In, doc.Style))
 			}
 			parts = append(parts, strings.Join(lines, "\n"))
+		case ast.ListBlock:
+			parts = append(parts, printListBlock(b, doc.Style))
+		case ast.VerseBlock:
+			parts = append(parts, printVerseBlock(b, doc.Style))
+		case ast.Epigraph:
+			quote := printInlines(b.In, doc.Style)
+			if len(b.Lines) > 0 {
+				quote = printVerseBlock(epigraphVerse(b), doc.Style)
+			}
+			parts = append(parts, quote+"\n— "+printInlines(b.Attribution, doc.Style))
+		case ast.FootnoteDef:
+			parts = append(parts, b.Marker+" "+printInlines(b.In, doc.Style))
 		case ast.SceneBreak:
-			parts = append(parts, "***")
+			parts = append(parts, sceneBreakMarker(b, opts, "***"))
 		}
 	}
 	return strings.Join(parts, "\n\n")
 }
 
-func printMarkdown(doc ast.Document) string {
-	parts := make([]string, 0, len(doc.Blocks))
-	for _, blk := range doc.Blocks {
+func printMarkdown(doc ast.Document, opts Options) string {
+	fields, blocks := documentMeta(doc, opts)
+	parts := make([]string, 0, len(blocks)+1)
+	if len(fields) > 0 {
+		parts = append(parts, printYAMLFrontMatter(fields))
+	}
+	for _, blk := range blocks {
 		switch b := blk.(type) {
 		case ast.TitleBlock:
-			parts = append(parts, "# "+printInlines(b.In, doc.Style))
+			parts = append(parts, printMarkdownTitleBlock(b, doc.Style)...)
 		case ast.Paragraph:
-			parts = append(parts, printInlines(b.In, doc.Style))
+			parts = append(parts, printMarkdownInlines(b.In, doc.Style))
 		case ast.Heading:
 			level := min(max(b.Level, 1), 6)
-			parts = append(parts, strings.Repeat("#", level)+" "+printInlines(b.In, doc.Style))
+			parts = append(parts, strings.Repeat("#", level)+" "+printMarkdownInlines(b.In, doc.Style))
 		case ast.ContentsBlock:
-			parts = append(parts, printMarkdownContentsBlock(b, doc.Style))
+			parts = append(parts, printMarkdownContentsBlock(b, doc.Style, opts))
 		case ast.MetaLineBlock:
-			value := printInlines(b.In, doc.Style)
+			value := printMarkdownInlines(b.In, doc.Style)
 			if value == "" {
 				parts = append(parts, "- **"+b.Key+":**")
 			} else {
 				parts = append(parts, "- **"+b.Key+":** "+value)
 			}
+		case ast.SpeakerTurn:
+			parts = append(parts, "**"+b.Speaker+":** "+printMarkdownInlines(b.In, doc.Style))
 		case ast.DialogueBlock:
 			lines := make([]string, 0, len(b.Turns))
 			for _, turn := range b.Turns {
-				lines = append(lines, printInlines(turn.In, doc.Style))
+				lines = append(lines, printMarkdownInlines(turn.In, doc.Style))
 			}
 			parts = append(parts, strings.Join(lines, "\n\n"))
+		case ast.ListBlock:
+			parts = append(parts, printMarkdownListBlock(b, doc.Style))
+		case ast.VerseBlock:
+			parts = append(parts, printMarkdownVerseBlock(b, doc.Style))
+		case ast.Epigraph:
+			quote := printMarkdownInlines(b.In, doc.Style)
+			if len(b.Lines) > 0 {
+				quote = strings.ReplaceAll(printMarkdownVerseBlock(epigraphVerse(b), doc.Style), "\n", "\n> ")
+			}
+			parts = append(parts, "> "+quote+"\n>\n> — "+printMarkdownInlines(b.Attribution, doc.Style))
+		case ast.FootnoteDef:
+			parts = append(parts, "[^"+markdownFootnoteLabel(b.Label, b.Scope)+"]: "+printMarkdownInlines(b.In, doc.Style))
 		case ast.SceneBreak:
-			parts = append(parts, "---")
+			parts = append(parts, markdownSceneBreak(sceneBreakMarker(b, opts, "---")))
 		}
 	}
 	return strings.Join(parts, "\n\n")
 }
 
-func printHTML(doc ast.Document) string {
+func printHTML(doc ast.Document, opts Options) string {
 	lines := make([]string, 0, len(doc.Blocks)+2)
-	lines = append(lines, "<article>")
-	for _, blk := range doc.Blocks {
+	fields, _ := documentMeta(doc, opts)
+	if len(fields) > 0 {
+		lines = append(lines, "<article itemscope>")
+	} else {
+		lines = append(lines, "<article>")
+	}
+	lines = append(lines, printHTMLMeta(fields)...)
+	anchors := collectHeadingAnchors(doc)
+	// sections holds the levels of the open <section> elements.
+	var sections []int
+	closeSections := func(level int) {
+		for len(sections) > 0 && sections[len(sections)-1] >= level {
+			sections = sections[:len(sections)-1]
+			lines = append(lines, strings.Repeat("  ", len(sections))+"  </section>")
+		}
+	}
+	for i, blk := range doc.Blocks {
+		if h, ok := blk.(ast.Heading); ok && opts.HTMLSections {
+			closeSections(h.Level)
+			lines = append(lines, strings.Repeat("  ", len(sections))+"  <section>")
+			sections = append(sections, h.Level)
+		}
+		start := len(lines)
 		switch b := blk.(type) {
 		case ast.TitleBlock:
-			lines = append(lines, "  <h1>"+escapeHTMLText(printInlines(b.In, doc.Style))+"</h1>")
+			lines = append(lines, printHTMLTitleBlock(b, doc.Style)...)
 		case ast.Paragraph:
-			lines = append(lines, "  <p>"+escapeHTMLText(printInlines(b.In, doc.Style))+"</p>")
+			lines = append(lines, "  <p>"+printHTMLInlines(b.In, doc.Style)+"</p>")
 		case ast.Heading:
 			level := min(max(b.Level, 1), 6)
-			lines = append(lines, fmt.Sprintf("  <h%d>%s</h%d>", level, escapeHTMLText(printInlines(b.In, doc.Style)), level))
+			lines = append(lines, fmt.Sprintf("  <h%d id=\"%s\">%s</h%d>", level, escapeHTMLText(anchors.ids[i]), printHTMLInlines(b.In, doc.Style), level))
 		case ast.ContentsBlock:
-			lines = append(lines, printHTMLContentsBlock(b, doc.Style)...)
+			lines = append(lines, printHTMLContentsBlock(b, doc, opts, anchors)...)
 		case ast.MetaLineBlock:
-			value := printInlines(b.In, doc.Style)
+			value := printHTMLInlines(b.In, doc.Style)
 			if value == "" {
 				lines = append(lines, "  <p class=\"meta\"><span class=\"key\">"+escapeHTMLText(b.Key)+":</span></p>")
 			} else {
-				lines = append(lines, "  <p class=\"meta\"><span class=\"key\">"+escapeHTMLText(b.Key)+":</span> "+escapeHTMLText(value)+"</p>")
+				lines = append(lines, "  <p class=\"meta\"><span class=\"key\">"+escapeHTMLText(b.Key)+":</span> "+value+"</p>")
 			}
+		case ast.SpeakerTurn:
+			lines = append(lines, "  <p class=\"speaker-turn\"><b class=\"speaker\">"+escapeHTMLText(b.Speaker)+":</b> "+printHTMLInlines(b.In, doc.Style)+"</p>")
 		case ast.DialogueBlock:
 			lines = append(lines, "  <div class=\"dialogue\">")
 			for _, turn := range b.Turns {
-				lines = append(lines, "    <p>"+escapeHTMLText(printInlines(turn.In, doc.Style))+"</p>")
+				lines = append(lines, "    <p>"+printHTMLInlines(turn.In, doc.Style)+"</p>")
 			}
 			lines = append(lines, "  </div>")
+		case ast.ListBlock:
+			lines = append(lines, printHTMLListBlock(b, doc.Style)...)
+		case ast.VerseBlock:
+			lines = append(lines, printHTMLVerseBlock(b, doc.Style)...)
+		case ast.Epigraph:
+			quote := printHTMLInlines(b.In, doc.Style)
+			if len(b.Lines) > 0 {
+				verse := make([]string, 0, len(b.Lines))
+				for _, line := range b.Lines {
+					verse = append(verse, strings.Repeat("&nbsp;", line.Indent)+printHTMLInlines(line.In, doc.Style))
+				}
+				quote = strings.Join(verse, "<br/>")
+			}
+			lines = append(lines,
+				"  <blockquote class=\"epigraph\">",
+				"    <p>"+quote+"</p>",
+				"    <cite>"+printHTMLInlines(b.Attribution, doc.Style)+"</cite>",
+				"  </blockquote>",
+			)
+		case ast.FootnoteDef:
+			id := footnoteID(b.Label, b.Scope)
+			lines = append(lines, "  <aside class=\"footnote\" id=\"fn-"+id+"\"><sup>"+escapeHTMLText(b.Label)+"</sup> "+
+				printHTMLInlines(b.In, doc.Style)+" <a href=\"#fnref-"+id+"\">↩</a></aside>")
 		case ast.SceneBreak:
-			lines = append(lines, "  <hr />")
+			lines = append(lines, "  "+htmlSceneBreak(b, opts))
+		}
+		if indent := strings.Repeat("  ", len(sections)); indent != "" {
+			for j := start; j < len(lines); j++ {
+				lines[j] = indent + lines[j]
+			}
 		}
 	}
+	closeSections(0)
 	lines = append(lines, "</article>")
 	return strings.Join(lines, "\n")
 }
 
-func printXML(doc ast.Document) string {
+func printXML(doc ast.Document, opts Options) string {
 	lines := make([]string, 0, len(doc.Blocks)+2)
 	lines = append(lines, "<document lang=\""+escapeXMLAttr(string(doc.Lang))+"\">")
+	if fields, _ := documentMeta(doc, opts); len(fields) > 0 {
+		lines = append(lines, printXMLMeta(fields)...)
+	}
 	for _, blk := range doc.Blocks {
 		switch b := blk.(type) {
 		case ast.TitleBlock:
-			lines = append(lines, "  <title>"+escapeXMLText(printInlines(b.In, doc.Style))+"</title>")
+			lines = append(lines, printXMLTitleBlock(b, doc.Style)...)
 		case ast.Paragraph:
-			lines = append(lines, "  <paragraph>"+escapeXMLText(printInlines(b.In, doc.Style))+"</paragraph>")
+			lines = append(lines, "  <paragraph>"+printXMLInlines(b.In, doc.Style)+"</paragraph>")
 		case ast.Heading:
 			level := max(b.Level, 1)
-			lines = append(lines, fmt.Sprintf("  <heading level=\"%d\">%s</heading>", level, escapeXMLText(printInlines(b.In, doc.Style))))
+			lines = append(lines, fmt.Sprintf("  <heading level=\"%d\">%s</heading>", level, printXMLInlines(b.In, doc.Style)))
 		case ast.ContentsBlock:
 			lines = append(lines, "  <contents>")
-			lines = append(lines, "    <title>"+escapeXMLText(printInlines(b.In, doc.Style))+"</title>")
+			lines = append(lines, "    <title>"+printXMLInlines(b.In, doc.Style)+"</title>")
 			for _, entry := range b.Entries {
 				level := max(entry.Level, 1)
-				lines = append(lines, fmt.Sprintf("    <entry level=\"%d\">%s</entry>", level, escapeXMLText(printInlines(entry.In, doc.Style))))
+				page := ""
+				if p := contentsPage(entry, opts); p != "" {
+					page = " page=\"" + escapeXMLAttr(p) + "\""
+				}
+				lines = append(lines, fmt.Sprintf("    <entry level=\"%d\"%s>%s</entry>", level, page, printXMLInlines(entry.In, doc.Style)))
 			}
 			lines = append(lines, "  </contents>")
 		case ast.MetaLineBlock:
-			lines = append(lines, "  <meta key=\""+escapeXMLAttr(b.Key)+"\">"+escapeXMLText(printInlines(b.In, doc.Style))+"</meta>")
+			attrs := " key=\"" + escapeXMLAttr(b.Key) + "\""
+			if b.Canonical != "" {
+				attrs += " canonical=\"" + escapeXMLAttr(b.Canonical) + "\""
+			}
+			lines = append(lines, "  <meta"+attrs+">"+printXMLInlines(b.In, doc.Style)+"</meta>")
+		case ast.SpeakerTurn:
+			lines = append(lines, "  <speaker-turn speaker=\""+escapeXMLAttr(b.Speaker)+"\">"+printXMLInlines(b.In, doc.Style)+"</speaker-turn>")
 		case ast.DialogueBlock:
 			lines = append(lines, "  <dialogue>")
 			for _, turn := range b.Turns {
-				lines = append(lines, "    <turn>"+escapeXMLText(printInlines(turn.In, doc.Style))+"</turn>")
+				lines = append(lines, "    <turn>"+printXMLInlines(turn.In, doc.Style)+"</turn>")
 			}
 			lines = append(lines, "  </dialogue>")
+		case ast.ListBlock:
+			lines = append(lines, "  <list kind=\""+listKindName(b.Kind)+"\">")
+			for _, item := range b.Items {
+				level := max(item.Level, 1)
+				lines = append(lines, fmt.Sprintf("    <item level=\"%d\" marker=\"%s\">%s</item>", level, escapeXMLAttr(item.Marker), printXMLInlines(item.In, doc.Style)))
+			}
+			lines = append(lines, "  </list>")
+		case ast.VerseBlock:
+			lines = append(lines, printXMLVerseBlock(b, doc.Style)...)
+		case ast.Epigraph:
+			lines = append(lines, "  <epigraph>")
+			if len(b.Lines) > 0 {
+				for _, line := range printXMLVerseBlock(epigraphVerse(b), doc.Style) {
+					lines = append(lines, "  "+line)
+				}
+			} else {
+				lines = append(lines, "    <text>"+printXMLInlines(b.In, doc.Style)+"</text>")
+			}
+			lines = append(lines,
+				"    <attribution>"+printXMLInlines(b.Attribution, doc.Style)+"</attribution>",
+				"  </epigraph>",
+			)
+		case ast.FootnoteDef:
+			lines = append(lines, "  <note label=\""+escapeXMLAttr(b.Label)+"\">"+printXMLInlines(b.In, doc.Style)+"</note>")
 		case ast.SceneBreak:
-			lines = append(lines, "  <scene-break marker=\""+escapeXMLAttr(b.Marker)+"\" />")
+			lines = append(lines, "  <scene-break marker=\""+escapeXMLAttr(sceneBreakMarker(b, opts, b.Marker))+"\" />")
 		}
 	}
 	lines = append(lines, "</document>")
 	return strings.Join(lines, "\n")
 }
 
-func printContentsBlock(b ast.ContentsBlock, style config.Style) string {
+func printContentsBlock(b ast.ContentsBlock, style config.Style, opts Options) string {
 	lines := make([]string, 0, len(b.Entries)+1)
 	lines = append(lines, printInlines(b.In, style))
 	for _, entry := range b.Entries {
-		lines = append(lines, printInlines(entry.In, style))
+		lines = append(lines, withLeaders(printInlines(entry.In, style), contentsPage(entry, opts), contentsWidth))
 	}
 	return strings.Join(lines, "\n")
 }
 
-func printMarkdownContentsBlock(b ast.ContentsBlock, style config.Style) string {
+func printMarkdownContentsBlock(b ast.ContentsBlock, style config.Style, opts Options) string {
 	lines := make([]string, 0, len(b.Entries)+1)
-	lines = append(lines, "## "+printInlines(b.In, style))
+	lines = append(lines, "## "+printMarkdownInlines(b.In, style))
 	for _, entry := range b.Entries {
 		indent := strings.Repeat("  ", max(entry.Level-1, 0))
-		lines = append(lines, indent+"- "+printInlines(entry.In, style))
+		text := printMarkdownInlines(entry.In, style)
+		lines = append(lines, indent+"- "+withLeaders(text, contentsPage(entry, opts), contentsWidth-len(indent)-2))
 	}
 	return strings.Join(lines, "\n")
 }
 
-func printHTMLContentsBlock(b ast.ContentsBlock, style config.Style) []string {
+func contentsPage(entry ast.ContentsEntry, opts Options) string {
+	if opts.ContentsPages == ContentsPagesOmit {
+		return ""
+	}
+	return entry.Page
+}
+
+// withLeaders right-aligns the page number to width with dot leaders,
+// keeping at least a few dots when the text is too long.
+func withLeaders(text, page string, width int) string {
+	if page == "" {
+		return text
+	}
+	dots := max(width-utf8.RuneCountInString(text)-utf8.RuneCountInString(page)-2, 4)
+	return text + " " + strings.Repeat(".", dots) + " " + page
+}
+
+func printHTMLContentsBlock(b ast.ContentsBlock, doc ast.Document, opts Options, anchors *headingAnchors) []string {
 	lines := make([]string, 0, len(b.Entries)+3)
 	lines = append(lines, "  <section class=\"contents\">")
-	lines = append(lines, "    <h2>"+escapeHTMLText(printInlines(b.In, style))+"</h2>")
+	lines = append(lines, "    <h2>"+printHTMLInlines(b.In, doc.Style)+"</h2>")
 	if len(b.Entries) > 0 {
-		tree := buildContentsTree(b.Entries, style)
+		tree := buildContentsTree(b.Entries, doc, opts, anchors)
 		lines = append(lines, renderContentsTreeHTML(tree, "    ")...)
 	}
 	lines = append(lines, "  </section>")
@@ -223,7 +427,7 @@ type contentsTreeNode struct {
 	Children []*contentsTreeNode
 }
 
-func buildContentsTree(entries []ast.ContentsEntry, style config.Style) []*contentsTreeNode {
+func buildContentsTree(entries []ast.ContentsEntry, doc ast.Document, opts Options, anchors *headingAnchors) []*contentsTreeNode {
 	if len(entries) == 0 {
 		return nil
 	}
@@ -249,7 +453,13 @@ func buildContentsTree(entries []ast.ContentsEntry, style config.Style) []*conte
 		}
 
 		node := &contentsTreeNode{
-			Text: escapeHTMLText(printInlines(entry.In, style)),
+			Text: printHTMLInlines(entry.In, doc.Style),
+		}
+		if id := anchors.link(entry, doc.Style, doc.Lang); id != "" {
+			node.Text = "<a href=\"#" + escapeHTMLText(id) + "\">" + node.Text + "</a>"
+		}
+		if page := contentsPage(entry, opts); page != "" {
+			node.Text += " <span class=\"page\">" + escapeHTMLText(page) + "</span>"
 		}
 		parent := stack[len(stack)-1]
 		parent.Children = append(parent.Children, node)
@@ -327,6 +537,14 @@ func printInlines(in []ast.Inline, style config.Style) string {
 			}
 		case ast.Ellipsis:
 			b.WriteRune('…')
+		case ast.Verbatim:
+			b.WriteString(it.S)
+		case ast.FootnoteRef:
+			b.WriteString(it.Marker)
+		case ast.Emphasis:
+			b.WriteString(it.Marker)
+			b.WriteString(printInlines(it.In, style))
+			b.WriteString(it.Marker)
 		case ast.ParenSpan:
 			b.WriteRune(it.Open)
 			b.WriteString(printInlines(it.In, style))
@@ -341,6 +559,149 @@ func printInlines(in []ast.Inline, style config.Style) string {
 	return b.String()
 }
 
+// printHTMLInlines escapes text like escapeHTMLText(printInlines(...)) but
+// renders URLs and e-mails as links and code spans as <code>.
+func printHTMLInlines(in []ast.Inline, style config.Style) string {
+	var b strings.Builder
+	for _, item := range in {
+		switch it := item.(type) {
+		case ast.Verbatim:
+			b.WriteString(printHTMLVerbatim(it))
+		case ast.FootnoteRef:
+			id := footnoteID(it.Label, it.Scope)
+			refID := id
+			if it.N > 1 {
+				refID += "-r" + strconv.Itoa(it.N)
+			}
+			b.WriteString("<sup><a href=\"#fn-" + id + "\" id=\"fnref-" + refID + "\">" + escapeHTMLText(it.Label) + "</a></sup>")
+		case ast.Emphasis:
+			tag := htmlEmphasisTags[it.Kind]
+			b.WriteString("<" + tag + ">" + printHTMLInlines(it.In, style) + "</" + tag + ">")
+		case ast.ParenSpan:
+			b.WriteString(escapeHTMLText(string(it.Open)))
+			b.WriteString(printHTMLInlines(it.In, style))
+			b.WriteString(escapeHTMLText(string(it.Close)))
+		case ast.QuoteSpan:
+			pair := pairForLevel(style, it.Level)
+			b.WriteString(escapeHTMLText(string(pair.Open)))
+			b.WriteString(printHTMLInlines(it.In, style))
+			b.WriteString(escapeHTMLText(string(pair.Close)))
+		default:
+			b.WriteString(escapeHTMLText(printInlines([]ast.Inline{item}, style)))
+		}
+	}
+	return b.String()
+}
+
+var htmlEmphasisTags = map[ast.EmphasisKind]string{
+	ast.EmphasisItalic: "em",
+	ast.EmphasisStrong: "strong",
+	ast.EmphasisStrike: "del",
+}
+
+var xmlEmphasisTags = map[ast.EmphasisKind]string{
+	ast.EmphasisItalic: "emphasis",
+	ast.EmphasisStrong: "strong",
+	ast.EmphasisStrike: "strikethrough",
+}
+
+// printXMLInlines escapes text like escapeXMLText(printInlines(...)) and
+// renders emphasis as elements.
+func printXMLInlines(in []ast.Inline, style config.Style) string {
+	var b strings.Builder
+	for _, item := range in {
+		switch it := item.(type) {
+		case ast.FootnoteRef:
+			b.WriteString("<note-ref label=\"" + escapeXMLAttr(it.Label) + "\" />")
+		case ast.Emphasis:
+			tag := xmlEmphasisTags[it.Kind]
+			b.WriteString("<" + tag + ">" + printXMLInlines(it.In, style) + "</" + tag + ">")
+		case ast.ParenSpan:
+			b.WriteString(escapeXMLText(string(it.Open)))
+			b.WriteString(printXMLInlines(it.In, style))
+			b.WriteString(escapeXMLText(string(it.Close)))
+		case ast.QuoteSpan:
+			pair := pairForLevel(style, it.Level)
+			b.WriteString(escapeXMLText(string(pair.Open)))
+			b.WriteString(printXMLInlines(it.In, style))
+			b.WriteString(escapeXMLText(string(pair.Close)))
+		default:
+			b.WriteString(escapeXMLText(printInlines([]ast.Inline{item}, style)))
+		}
+	}
+	return b.String()
+}
+
+// printMarkdownInlines is printInlines with footnote references written as
+// `[^label]`.
+func printMarkdownInlines(in []ast.Inline, style config.Style) string {
+	var b strings.Builder
+	for _, item := range in {
+		switch it := item.(type) {
+		case ast.FootnoteRef:
+			b.WriteString("[^" + markdownFootnoteLabel(it.Label, it.Scope) + "]")
+		case ast.Emphasis:
+			b.WriteString(it.Marker + printMarkdownInlines(it.In, style) + it.Marker)
+		case ast.ParenSpan:
+			b.WriteRune(it.Open)
+			b.WriteString(printMarkdownInlines(it.In, style))
+			b.WriteRune(it.Close)
+		case ast.QuoteSpan:
+			pair := pairForLevel(style, it.Level)
+			b.WriteRune(pair.Open)
+			b.WriteString(printMarkdownInlines(it.In, style))
+			b.WriteRune(pair.Close)
+		default:
+			b.WriteString(printInlines([]ast.Inline{item}, style))
+		}
+	}
+	return b.String()
+}
+
+// footnoteID turns a label into an anchor id: digits stay, asterisks become
+// star, star2, star3. A label defined again gets the scope as a suffix:
+// the second note 1 is 1-2.
+func footnoteID(label string, scope int) string {
+	id := label
+	if strings.Trim(label, "*") == "" {
+		id = "star"
+		if len(label) > 1 {
+			id += strconv.Itoa(len(label))
+		}
+	}
+	if scope > 1 {
+		id += "-" + strconv.Itoa(scope)
+	}
+	return id
+}
+
+// markdownFootnoteLabel is the `[^label]` name of a note, suffixed like
+// footnoteID when the label is defined again.
+func markdownFootnoteLabel(label string, scope int) string {
+	if scope > 1 {
+		return label + "-" + strconv.Itoa(scope)
+	}
+	return label
+}
+
+func printHTMLVerbatim(v ast.Verbatim) string {
+	text := escapeHTMLText(v.S)
+	switch v.Kind {
+	case ast.VerbatimURL:
+		href := v.S
+		if !strings.Contains(href, "://") {
+			href = "http://" + href
+		}
+		return "<a href=\"" + escapeHTMLText(href) + "\">" + text + "</a>"
+	case ast.VerbatimEmail:
+		return "<a href=\"mailto:" + escapeHTMLText(v.S) + "\">" + text + "</a>"
+	case ast.VerbatimCode:
+		return "<code>" + escapeHTMLText(strings.Trim(v.S, "`")) + "</code>"
+	default:
+		return text
+	}
+}
+
 func pairForLevel(style config.Style, level ast.QuoteLevel) config.QuotePair {
 	if level == ast.QuoteSecondary {
 		return style.Inner
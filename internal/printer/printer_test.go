@@ -3,6 +3,7 @@ package printer
 import (
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/n0madic/txtfmt/internal/ast"
 	"github.com/n0madic/txtfmt/internal/config"
@@ -101,3 +102,522 @@ func TestPrintMarkdownDialogueNoBlockquoteAndHardBreaks(t *testing.T) {
 		t.Fatalf("expected paragraph break between dialogue turns, got:\n%s", out)
 	}
 }
+
+func TestPrintHTMLLinksVerbatim(t *testing.T) {
+	cfg, err := config.New("en", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	doc := ast.Document{
+		Lang:  cfg.Lang,
+		Style: cfg.Style,
+		Blocks: []ast.Block{
+			ast.Paragraph{In: []ast.Inline{
+				ast.Verbatim{Kind: ast.VerbatimURL, S: "www.example.com/?a=1&b=2"},
+				ast.Space{Kind: ast.SpaceNormal},
+				ast.Verbatim{Kind: ast.VerbatimEmail, S: "user@mail.ru"},
+				ast.Space{Kind: ast.SpaceNormal},
+				ast.Verbatim{Kind: ast.VerbatimCode, S: "`a<b`"},
+			}},
+		},
+	}
+
+	out := PrintWithFormat(doc, FormatHTML)
+	for _, want := range []string{
+		`<a href="http://www.example.com/?a=1&amp;b=2">www.example.com/?a=1&amp;b=2</a>`,
+		`<a href="mailto:user@mail.ru">user@mail.ru</a>`,
+		`<code>a&lt;b</code>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %s in:\n%s", want, out)
+		}
+	}
+	if plain := PrintWithFormat(doc, FormatPlain); plain != "www.example.com/?a=1&b=2 user@mail.ru `a<b`" {
+		t.Fatalf("unexpected plain output: %q", plain)
+	}
+}
+
+func TestPrintEmphasis(t *testing.T) {
+	cfg, err := config.New("en", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	doc := ast.Document{
+		Lang:  cfg.Lang,
+		Style: cfg.Style,
+		Blocks: []ast.Block{
+			ast.Paragraph{In: []ast.Inline{
+				ast.Emphasis{Kind: ast.EmphasisStrong, Marker: "**", In: []ast.Inline{ast.Word{S: "bold"}}},
+				ast.Space{Kind: ast.SpaceNormal},
+				ast.Emphasis{Kind: ast.EmphasisItalic, Marker: "_", In: []ast.Inline{ast.Word{S: "it"}}},
+			}},
+		},
+	}
+
+	cases := map[Format]string{
+		FormatPlain:    "**bold** _it_",
+		FormatMarkdown: "**bold** _it_",
+		FormatHTML:     "<strong>bold</strong> <em>it</em>",
+		FormatXML:      "<strong>bold</strong> <emphasis>it</emphasis>",
+	}
+	for format, want := range cases {
+		if out := PrintWithFormat(doc, format); !strings.Contains(out, want) {
+			t.Fatalf("%s: expected %q in:\n%s", format, want, out)
+		}
+	}
+}
+
+func TestPrintNestedLists(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	doc := ast.Document{
+		Lang:  cfg.Lang,
+		Style: cfg.Style,
+		Blocks: []ast.Block{
+			ast.ListBlock{Kind: ast.ListOrdered, Items: []ast.ListItem{
+				{Level: 1, Kind: ast.ListOrdered, Marker: "1.", In: []ast.Inline{ast.Word{S: "Один"}}},
+				{Level: 2, Kind: ast.ListOrdered, Marker: "а)", In: []ast.Inline{ast.Word{S: "первое"}}},
+				{Level: 1, Kind: ast.ListOrdered, Marker: "2.", In: []ast.Inline{ast.Word{S: "Два"}}},
+			}},
+		},
+	}
+
+	md := PrintWithFormat(doc, FormatMarkdown)
+	if md != "1. Один\n   1. первое\n2. Два" {
+		t.Fatalf("unexpected markdown:\n%s", md)
+	}
+
+	html := PrintWithFormat(doc, FormatHTML)
+	for _, want := range []string{"<ol>", "<li>Один\n", "<ol type=\"a\">", "<li>первое</li>", "<li>Два</li>"} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("expected %q in:\n%s", want, html)
+		}
+	}
+
+	xml := PrintWithFormat(doc, FormatXML)
+	if !strings.Contains(xml, `<item level="2" marker="а)">первое</item>`) {
+		t.Fatalf("unexpected xml:\n%s", xml)
+	}
+}
+
+func TestPrintFootnotes(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	doc := ast.Document{
+		Lang:  cfg.Lang,
+		Style: cfg.Style,
+		Blocks: []ast.Block{
+			ast.Paragraph{In: []ast.Inline{
+				ast.Word{S: "слово"},
+				ast.FootnoteRef{Label: "1", Marker: "¹"},
+				ast.Punct{Ch: '.'},
+			}},
+			ast.FootnoteDef{Label: "1", Marker: "¹", In: []ast.Inline{ast.Word{S: "Сноска"}}},
+		},
+	}
+
+	cases := map[Format]string{
+		FormatPlain:    "слово¹.\n\n¹ Сноска",
+		FormatMarkdown: "слово[^1].\n\n[^1]: Сноска",
+		FormatHTML: "<p>слово<sup><a href=\"#fn-1\" id=\"fnref-1\">1</a></sup>.</p>\n" +
+			"  <aside class=\"footnote\" id=\"fn-1\"><sup>1</sup> Сноска <a href=\"#fnref-1\">↩</a></aside>",
+		FormatXML: "<paragraph>слово<note-ref label=\"1\" />.</paragraph>\n  <note label=\"1\">Сноска</note>",
+	}
+	for format, want := range cases {
+		if out := PrintWithFormat(doc, format); !strings.Contains(out, want) {
+			t.Fatalf("%s: expected %q in:\n%s", format, want, out)
+		}
+	}
+}
+
+func TestPrintFootnotesKeepIDsApart(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	note := func(scope int, text string) ast.FootnoteDef {
+		return ast.FootnoteDef{Label: "1", Marker: "[1]", Scope: scope, In: []ast.Inline{ast.Word{S: text}}}
+	}
+	doc := ast.Document{
+		Lang:  cfg.Lang,
+		Style: cfg.Style,
+		Blocks: []ast.Block{
+			ast.Paragraph{In: []ast.Inline{
+				ast.Word{S: "раз"},
+				ast.FootnoteRef{Label: "1", Marker: "[1]", Scope: 1, N: 1},
+				ast.Space{},
+				ast.Word{S: "два"},
+				ast.FootnoteRef{Label: "1", Marker: "[1]", Scope: 1, N: 2},
+			}},
+			note(1, "Первая"),
+			ast.ListBlock{Kind: ast.ListUnordered, Items: []ast.ListItem{{Level: 1, Marker: "-", In: []ast.Inline{
+				ast.Word{S: "три"},
+				ast.FootnoteRef{Label: "1", Marker: "[1]", Scope: 2, N: 1},
+			}}}},
+			note(2, "Вторая"),
+		},
+	}
+
+	html := PrintWithFormat(doc, FormatHTML)
+	for _, want := range []string{
+		"раз<sup><a href=\"#fn-1\" id=\"fnref-1\">1</a></sup>",
+		"два<sup><a href=\"#fn-1\" id=\"fnref-1-r2\">1</a></sup>",
+		"три<sup><a href=\"#fn-1-2\" id=\"fnref-1-2\">1</a></sup>",
+		"<aside class=\"footnote\" id=\"fn-1-2\"><sup>1</sup> Вторая <a href=\"#fnref-1-2\">↩</a></aside>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("html: expected %q in:\n%s", want, html)
+		}
+	}
+
+	want := "раз[^1] два[^1]\n\n[^1]: Первая\n\n- три[^1-2]\n\n[^1-2]: Вторая"
+	if out := PrintWithFormat(doc, FormatMarkdown); out != want {
+		t.Fatalf("markdown: expected %q, got %q", want, out)
+	}
+}
+
+func TestPrintVerseKeepsLineBreaks(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	doc := ast.Document{
+		Lang:  cfg.Lang,
+		Style: cfg.Style,
+		Blocks: []ast.Block{
+			ast.VerseBlock{Stanzas: []ast.Stanza{
+				{Lines: []ast.VerseLine{
+					{In: []ast.Inline{ast.Word{S: "Раз"}}},
+					{Indent: 2, In: []ast.Inline{ast.Word{S: "Два"}}},
+				}},
+				{Lines: []ast.VerseLine{
+					{In: []ast.Inline{ast.Word{S: "Три"}}},
+				}},
+			}},
+		},
+	}
+
+	cases := map[Format]string{
+		FormatPlain:    "Раз\n  Два\n\nТри",
+		FormatMarkdown: "Раз  \n\u00A0\u00A0Два\n\nТри",
+		FormatHTML: "    <div class=\"stanza\">\n      Раз<br/>\n      &nbsp;&nbsp;Два\n    </div>\n" +
+			"    <div class=\"stanza\">\n      Три\n    </div>",
+		FormatXML: "<stanza>\n      <v>Раз</v>\n      <v indent=\"2\">Два</v>\n    </stanza>",
+	}
+	for format, want := range cases {
+		if out := PrintWithFormat(doc, format); !strings.Contains(out, want) {
+			t.Fatalf("%s: expected %q in:\n%s", format, want, out)
+		}
+	}
+}
+
+func TestPrintEpigraph(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	doc := ast.Document{
+		Lang:  cfg.Lang,
+		Style: cfg.Style,
+		Blocks: []ast.Block{
+			ast.Epigraph{
+				In:          []ast.Inline{ast.Word{S: "Цитата"}},
+				Attribution: []ast.Inline{ast.Word{S: "Автор"}},
+			},
+		},
+	}
+
+	cases := map[Format]string{
+		FormatPlain:    "Цитата\n— Автор",
+		FormatMarkdown: "> Цитата\n>\n> — Автор",
+		FormatHTML:     "<blockquote class=\"epigraph\">\n    <p>Цитата</p>\n    <cite>Автор</cite>\n  </blockquote>",
+		FormatXML:      "<epigraph>\n    <text>Цитата</text>\n    <attribution>Автор</attribution>\n  </epigraph>",
+	}
+	for format, want := range cases {
+		if out := PrintWithFormat(doc, format); !strings.Contains(out, want) {
+			t.Fatalf("%s: expected %q in:\n%s", format, want, out)
+		}
+	}
+}
+
+func TestPrintSceneBreakPolicy(t *testing.T) {
+	doc := ast.Document{
+		Blocks: []ast.Block{
+			ast.SceneBreak{Marker: "x x x"},
+			ast.SceneBreak{Marker: "⁂"},
+			ast.SceneBreak{Marker: "-----"},
+		},
+	}
+
+	if out := Print(doc); out != "***\n\n***\n\n***" {
+		t.Fatalf("default: unexpected plain output %q", out)
+	}
+	if out := PrintWithFormat(doc, FormatMarkdown); out != "---\n\n---\n\n---" {
+		t.Fatalf("default: unexpected markdown output %q", out)
+	}
+	if out := PrintWithFormat(doc, FormatHTML); strings.Count(out, "<hr />") != 3 {
+		t.Fatalf("default: expected rules in HTML:\n%s", out)
+	}
+	if out := PrintWithFormat(doc, FormatXML); !strings.Contains(out, "<scene-break marker=\"x x x\" />") {
+		t.Fatalf("default: expected source marker in XML:\n%s", out)
+	}
+	keep := Options{SceneBreak: SceneBreakKeep}
+	if out := PrintWithOptions(doc, FormatPlain, keep); out != "x x x\n\n⁂\n\n-----" {
+		t.Fatalf("keep: unexpected plain output %q", out)
+	}
+	if out := PrintWithOptions(doc, FormatPlain, Options{SceneBreak: "* * *"}); out != "* * *\n\n* * *\n\n* * *" {
+		t.Fatalf("house style: unexpected plain output %q", out)
+	}
+	if out := PrintWithOptions(doc, FormatMarkdown, keep); out != "x x x\n\n⁂\n\n-----" {
+		t.Fatalf("unexpected markdown output %q", out)
+	}
+	if out := PrintWithOptions(ast.Document{Blocks: []ast.Block{ast.SceneBreak{Marker: "~~~"}}}, FormatMarkdown, keep); out != "\\~~~" {
+		t.Fatalf("expected escaped marker, got %q", out)
+	}
+
+	html := PrintWithOptions(doc, FormatHTML, Options{SceneBreak: SceneBreakKeep, SceneBreakClass: "sep"})
+	for _, want := range []string{"<p class=\"sep\">x x x</p>", "<p class=\"sep\">⁂</p>", "<hr class=\"sep\" />"} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("expected %q in:\n%s", want, html)
+		}
+	}
+	if out := PrintWithOptions(doc, FormatXML, keep); !strings.Contains(out, "<scene-break marker=\"⁂\" />") {
+		t.Fatalf("expected source marker in XML:\n%s", out)
+	}
+}
+
+func TestPrintFrontMatter(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	doc := ast.Document{
+		Lang:  cfg.Lang,
+		Style: cfg.Style,
+		Meta:  []ast.MetaField{{Key: "tags", Items: []string{"проза", "ночь"}}},
+		Blocks: []ast.Block{
+			ast.TitleBlock{In: []ast.Inline{ast.Word{S: "Дозор"}}},
+			ast.MetaLineBlock{Key: "Год", In: []ast.Inline{ast.Word{S: "1998"}}},
+			ast.Paragraph{In: []ast.Inline{ast.Word{S: "Текст"}}},
+		},
+	}
+	opts := Options{FrontMatter: true}
+
+	md := PrintWithOptions(doc, FormatMarkdown, opts)
+	if md != "---\ntags:\n  - проза\n  - ночь\ntitle: Дозор\nГод: \"1998\"\n---\n\nТекст" {
+		t.Fatalf("unexpected markdown front matter %q", md)
+	}
+	if plain := Print(doc); !strings.HasPrefix(plain, "---\ntags:\n  - проза\n  - ночь\n---\n\nДозор") {
+		t.Fatalf("expected parsed front matter kept in plain output, got %q", plain)
+	}
+
+	html := PrintWithOptions(doc, FormatHTML, opts)
+	for _, want := range []string{"<article itemscope>", "<meta itemprop=\"tags\" content=\"проза, ночь\" />", "<meta itemprop=\"title\" content=\"Дозор\" />", "<h1>Дозор</h1>"} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("expected %q in:\n%s", want, html)
+		}
+	}
+	xml := PrintWithOptions(doc, FormatXML, opts)
+	if !strings.Contains(xml, "<metadata>\n    <field name=\"tags\">\n      <item>проза</item>") ||
+		!strings.Contains(xml, "<field name=\"Год\">1998</field>") {
+		t.Fatalf("unexpected XML metadata:\n%s", xml)
+	}
+}
+
+func TestPrintStructuredTitlePage(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	doc := ast.Document{
+		Lang:  cfg.Lang,
+		Style: cfg.Style,
+		Blocks: []ast.Block{
+			ast.TitleBlock{
+				In: []ast.Inline{ast.Word{S: "Дозор"}},
+				Fields: []ast.TitleField{
+					{Kind: ast.TitleAuthor, Before: true, In: []ast.Inline{ast.Word{S: "Автор"}}},
+					{Kind: ast.TitleYear, Key: "Год", In: []ast.Inline{ast.Word{S: "1998"}}},
+				},
+			},
+		},
+	}
+
+	cases := map[Format]string{
+		FormatPlain: "Автор\nДозор\n\nГод: 1998",
+		FormatHTML:  "<header class=\"title-page\">\n    <p class=\"author\">Автор</p>\n    <h1>Дозор</h1>\n    <p class=\"year\"><span class=\"key\">Год:</span> 1998</p>\n  </header>",
+		FormatXML:   "<title-page>\n    <author>Автор</author>\n    <title>Дозор</title>\n    <year key=\"Год\">1998</year>\n  </title-page>",
+	}
+	for format, want := range cases {
+		if out := PrintWithFormat(doc, format); !strings.Contains(out, want) {
+			t.Fatalf("%s: expected %q in:\n%s", format, want, out)
+		}
+	}
+	if md := PrintWithOptions(doc, FormatMarkdown, Options{FrontMatter: true}); md != "---\ntitle: Дозор\nauthor: Автор\nyear: \"1998\"\n---" {
+		t.Fatalf("unexpected front matter %q", md)
+	}
+}
+
+func TestPrintSpeakerTurn(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	doc := ast.Document{
+		Lang:  cfg.Lang,
+		Style: cfg.Style,
+		Blocks: []ast.Block{
+			ast.SpeakerTurn{Speaker: "ИВАНОВ", In: []ast.Inline{ast.Word{S: "Да"}, ast.Punct{Ch: '.'}}},
+		},
+	}
+
+	cases := map[Format]string{
+		FormatPlain:    "ИВАНОВ: Да.",
+		FormatMarkdown: "**ИВАНОВ:** Да.",
+		FormatHTML:     "<p class=\"speaker-turn\"><b class=\"speaker\">ИВАНОВ:</b> Да.</p>",
+		FormatXML:      "<speaker-turn speaker=\"ИВАНОВ\">Да.</speaker-turn>",
+	}
+	for format, want := range cases {
+		if out := PrintWithFormat(doc, format); !strings.Contains(out, want) {
+			t.Fatalf("%s: expected %q in:\n%s", format, want, out)
+		}
+	}
+}
+
+func TestPrintContentsPages(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	doc := ast.Document{
+		Lang:  cfg.Lang,
+		Style: cfg.Style,
+		Blocks: []ast.Block{
+			ast.ContentsBlock{
+				In:      []ast.Inline{ast.Word{S: "Содержание"}},
+				Entries: []ast.ContentsEntry{{Level: 2, In: []ast.Inline{ast.Word{S: "Начало"}}, Page: "5"}},
+			},
+		},
+	}
+
+	plain := PrintWithFormat(doc, FormatPlain)
+	line := strings.Split(plain, "\n")[1]
+	if !strings.HasPrefix(line, "Начало ....") || !strings.HasSuffix(line, " 5") || utf8.RuneCountInString(line) != contentsWidth {
+		t.Fatalf("unexpected plain entry %q", line)
+	}
+	if xml := PrintWithFormat(doc, FormatXML); !strings.Contains(xml, "<entry level=\"2\" page=\"5\">Начало</entry>") {
+		t.Fatalf("unexpected XML contents:\n%s", xml)
+	}
+	if html := PrintWithFormat(doc, FormatHTML); !strings.Contains(html, "<li>Начало <span class=\"page\">5</span>") {
+		t.Fatalf("unexpected HTML contents:\n%s", html)
+	}
+	for _, format := range []Format{FormatPlain, FormatMarkdown, FormatHTML, FormatXML} {
+		if out := PrintWithOptions(doc, format, Options{ContentsPages: ContentsPagesOmit}); strings.Contains(out, "5") {
+			t.Fatalf("%s: expected no page number in:\n%s", format, out)
+		}
+	}
+}
+
+func TestPrintHTMLHeadingAnchors(t *testing.T) {
+	cfg, err := config.New("ru", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	heading := func(level int, words ...string) ast.Heading {
+		var in []ast.Inline
+		for i, w := range words {
+			if i > 0 {
+				in = append(in, ast.Space{Kind: ast.SpaceNormal})
+			}
+			in = append(in, ast.Word{S: w})
+		}
+		return ast.Heading{Level: level, In: in}
+	}
+	doc := ast.Document{
+		Lang:  cfg.Lang,
+		Style: cfg.Style,
+		Blocks: []ast.Block{
+			ast.ContentsBlock{
+				In: []ast.Inline{ast.Word{S: "Содержание"}},
+				Entries: []ast.ContentsEntry{
+					{Level: 1, In: heading(1, "Часть", "первая").In},
+					{Level: 2, In: heading(2, "Глава", "Щука").In},
+				},
+			},
+			heading(1, "Часть", "первая"),
+			heading(2, "Глава", "Щука"),
+			ast.Paragraph{In: []ast.Inline{ast.Word{S: "Текст"}}},
+			heading(2, "Глава", "Щука"),
+		},
+	}
+
+	html := PrintWithFormat(doc, FormatHTML)
+	for _, want := range []string{
+		"<li><a href=\"#chast-pervaia\">Часть первая</a>",
+		"<li><a href=\"#glava-shchuka\">Глава Щука</a>",
+		"<h1 id=\"chast-pervaia\">Часть первая</h1>",
+		"<h2 id=\"glava-shchuka\">Глава Щука</h2>",
+		"<h2 id=\"glava-shchuka-2\">Глава Щука</h2>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("expected %q in:\n%s", want, html)
+		}
+	}
+
+	sections := PrintWithOptions(doc, FormatHTML, Options{HTMLSections: true})
+	want := "" +
+		"  <section>\n" +
+		"    <h1 id=\"chast-pervaia\">Часть первая</h1>\n" +
+		"    <section>\n" +
+		"      <h2 id=\"glava-shchuka\">Глава Щука</h2>\n" +
+		"      <p>Текст</p>\n" +
+		"    </section>\n" +
+		"    <section>\n" +
+		"      <h2 id=\"glava-shchuka-2\">Глава Щука</h2>\n" +
+		"    </section>\n" +
+		"  </section>\n" +
+		"</article>"
+	if !strings.HasSuffix(sections, want) {
+		t.Fatalf("unexpected sections:\n%s", sections)
+	}
+}
+
+func TestPrintHTMLHeadingAnchorsStayUnique(t *testing.T) {
+	heading := func(words ...string) ast.Heading {
+		return ast.Heading{Level: 2, In: []ast.Inline{ast.Word{S: words[0]}, ast.Space{Kind: ast.SpaceNormal}, ast.Word{S: words[1]}}}
+	}
+	doc := ast.Document{
+		Lang: config.LangRU,
+		Blocks: []ast.Block{
+			heading("ГЛАВА", "1"),
+			heading("ГЛАВА", "1"),
+			heading("ГЛАВА", "1-2"),
+		},
+	}
+
+	a := collectHeadingAnchors(doc)
+	seen := map[string]bool{}
+	for i := range doc.Blocks {
+		id := a.ids[i]
+		if id == "" || seen[id] {
+			t.Fatalf("expected unique ids, got %#v", a.ids)
+		}
+		seen[id] = true
+	}
+}
@@ -0,0 +1,71 @@
+package printer
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+)
+
+// sceneBreakMarker applies the -scene-break policy: the format's own marker
+// when unset, the source marker with SceneBreakKeep or a house-style
+// replacement.
+func sceneBreakMarker(b ast.SceneBreak, opts Options, def string) string {
+	switch opts.SceneBreak {
+	case "":
+		return def
+	case SceneBreakKeep:
+		if b.Marker == "" {
+			return def
+		}
+		return b.Marker
+	}
+	return opts.SceneBreak
+}
+
+// markdownSceneBreak keeps thematic breaks (`***`, `- - -`) as they are;
+// any other marker is printed as text with its first character escaped so
+// `~~~` or `===` can't turn into a code fence or a heading.
+func markdownSceneBreak(marker string) string {
+	if isThematicBreak(marker) {
+		return marker
+	}
+	r, _ := utf8.DecodeRuneInString(marker)
+	if r < utf8.RuneSelf && strings.ContainsRune("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", r) {
+		return "\\" + marker
+	}
+	return marker
+}
+
+// htmlSceneBreak draws rule-like markers as <hr /> and ornaments such as ⁂
+// or § as a centered paragraph.
+func htmlSceneBreak(b ast.SceneBreak, opts Options) string {
+	marker := sceneBreakMarker(b, opts, "***")
+	class := opts.SceneBreakClass
+	if isRuleMarker(marker) {
+		if class == "" {
+			return "<hr />"
+		}
+		return "<hr class=\"" + escapeHTMLText(class) + "\" />"
+	}
+	if class == "" {
+		class = "scene-break"
+	}
+	return "<p class=\"" + escapeHTMLText(class) + "\">" + escapeHTMLText(marker) + "</p>"
+}
+
+func isThematicBreak(marker string) bool {
+	compact := strings.ReplaceAll(marker, " ", "")
+	if utf8.RuneCountInString(compact) < 3 {
+		return false
+	}
+	first := compact[0]
+	if first != '*' && first != '-' && first != '_' {
+		return false
+	}
+	return strings.Trim(compact, string(first)) == ""
+}
+
+func isRuleMarker(marker string) bool {
+	return strings.Trim(marker, "*-—–_=~ ") == ""
+}
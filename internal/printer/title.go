@@ -0,0 +1,121 @@
+package printer
+
+import (
+	"strings"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+	"github.com/n0madic/txtfmt/internal/config"
+)
+
+// titleLines splits the title page into the bare lines around the title and
+// the `Key: value` fields that follow it.
+func titleLines(b ast.TitleBlock) (before, after, keyed []ast.TitleField) {
+	for _, f := range b.Fields {
+		switch {
+		case f.Key != "":
+			keyed = append(keyed, f)
+		case f.Before:
+			before = append(before, f)
+		default:
+			after = append(after, f)
+		}
+	}
+	return before, after, keyed
+}
+
+func printTitleBlock(b ast.TitleBlock, style config.Style) []string {
+	before, after, keyed := titleLines(b)
+	lines := make([]string, 0, len(before)+len(after)+1)
+	for _, f := range before {
+		lines = append(lines, printInlines(f.In, style))
+	}
+	lines = append(lines, printInlines(b.In, style))
+	for _, f := range after {
+		lines = append(lines, printInlines(f.In, style))
+	}
+	parts := []string{strings.Join(lines, "\n")}
+	for _, f := range keyed {
+		parts = append(parts, f.Key+": "+printInlines(f.In, style))
+	}
+	return parts
+}
+
+func printMarkdownTitleBlock(b ast.TitleBlock, style config.Style) []string {
+	before, after, keyed := titleLines(b)
+	var parts []string
+	for _, f := range before {
+		parts = append(parts, printMarkdownInlines(f.In, style))
+	}
+	parts = append(parts, "# "+printMarkdownInlines(b.In, style))
+	for _, f := range after {
+		parts = append(parts, printMarkdownInlines(f.In, style))
+	}
+	for _, f := range keyed {
+		parts = append(parts, "- **"+f.Key+":** "+printMarkdownInlines(f.In, style))
+	}
+	return parts
+}
+
+func printHTMLTitleBlock(b ast.TitleBlock, style config.Style) []string {
+	if len(b.Fields) == 0 {
+		return []string{"  <h1>" + printHTMLInlines(b.In, style) + "</h1>"}
+	}
+	before, after, keyed := titleLines(b)
+	field := func(f ast.TitleField) string {
+		text := printHTMLInlines(f.In, style)
+		if f.Key != "" {
+			text = "<span class=\"key\">" + escapeHTMLText(f.Key) + ":</span> " + text
+		}
+		return "    <p class=\"" + titleFieldName(f.Kind) + "\">" + text + "</p>"
+	}
+	lines := []string{"  <header class=\"title-page\">"}
+	for _, f := range before {
+		lines = append(lines, field(f))
+	}
+	lines = append(lines, "    <h1>"+printHTMLInlines(b.In, style)+"</h1>")
+	for _, f := range append(after, keyed...) {
+		lines = append(lines, field(f))
+	}
+	return append(lines, "  </header>")
+}
+
+func printXMLTitleBlock(b ast.TitleBlock, style config.Style) []string {
+	if len(b.Fields) == 0 {
+		return []string{"  <title>" + printXMLInlines(b.In, style) + "</title>"}
+	}
+	before, after, keyed := titleLines(b)
+	field := func(f ast.TitleField) string {
+		tag := titleFieldName(f.Kind)
+		attr := ""
+		if f.Key != "" {
+			attr = " key=\"" + escapeXMLAttr(f.Key) + "\""
+		}
+		return "    <" + tag + attr + ">" + printXMLInlines(f.In, style) + "</" + tag + ">"
+	}
+	lines := []string{"  <title-page>"}
+	for _, f := range before {
+		lines = append(lines, field(f))
+	}
+	lines = append(lines, "    <title>"+printXMLInlines(b.In, style)+"</title>")
+	for _, f := range append(after, keyed...) {
+		lines = append(lines, field(f))
+	}
+	return append(lines, "  </title-page>")
+}
+
+func titleFieldName(kind ast.TitleFieldKind) string {
+	switch kind {
+	case ast.TitleAuthor:
+		return "author"
+	case ast.TitleSubtitle:
+		return "subtitle"
+	case ast.TitleSeries:
+		return "series"
+	case ast.TitleTranslator:
+		return "translator"
+	case ast.TitleYear:
+		return "year"
+	default:
+		return "field"
+	}
+}
@@ -0,0 +1,76 @@
+package printer
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+	"github.com/n0madic/txtfmt/internal/config"
+)
+
+func printVerseBlock(b ast.VerseBlock, style config.Style) string {
+	stanzas := make([]string, 0, len(b.Stanzas))
+	for _, stanza := range b.Stanzas {
+		lines := make([]string, 0, len(stanza.Lines))
+		for _, line := range stanza.Lines {
+			lines = append(lines, strings.Repeat(" ", line.Indent)+printInlines(line.In, style))
+		}
+		stanzas = append(stanzas, strings.Join(lines, "\n"))
+	}
+	return strings.Join(stanzas, "\n\n")
+}
+
+// printMarkdownVerseBlock ends lines with two spaces (hard break) and indents
+// with NBSP so deep indents don't turn into code blocks.
+func printMarkdownVerseBlock(b ast.VerseBlock, style config.Style) string {
+	stanzas := make([]string, 0, len(b.Stanzas))
+	for _, stanza := range b.Stanzas {
+		lines := make([]string, 0, len(stanza.Lines))
+		for _, line := range stanza.Lines {
+			lines = append(lines, strings.Repeat("\u00A0", line.Indent)+printMarkdownInlines(line.In, style))
+		}
+		stanzas = append(stanzas, strings.Join(lines, "  \n"))
+	}
+	return strings.Join(stanzas, "\n\n")
+}
+
+func printHTMLVerseBlock(b ast.VerseBlock, style config.Style) []string {
+	lines := make([]string, 0, len(b.Stanzas)*6+2)
+	lines = append(lines, "  <div class=\"verse\">")
+	for _, stanza := range b.Stanzas {
+		lines = append(lines, "    <div class=\"stanza\">")
+		for i, line := range stanza.Lines {
+			text := strings.Repeat("&nbsp;", line.Indent) + printHTMLInlines(line.In, style)
+			if i+1 < len(stanza.Lines) {
+				text += "<br/>"
+			}
+			lines = append(lines, "      "+text)
+		}
+		lines = append(lines, "    </div>")
+	}
+	lines = append(lines, "  </div>")
+	return lines
+}
+
+func printXMLVerseBlock(b ast.VerseBlock, style config.Style) []string {
+	lines := make([]string, 0, len(b.Stanzas)*6+2)
+	lines = append(lines, "  <verse>")
+	for _, stanza := range b.Stanzas {
+		lines = append(lines, "    <stanza>")
+		for _, line := range stanza.Lines {
+			attrs := ""
+			if line.Indent > 0 {
+				attrs = " indent=\"" + strconv.Itoa(line.Indent) + "\""
+			}
+			lines = append(lines, "      <v"+attrs+">"+printXMLInlines(line.In, style)+"</v>")
+		}
+		lines = append(lines, "    </stanza>")
+	}
+	lines = append(lines, "  </verse>")
+	return lines
+}
+
+// epigraphVerse is the verse quotation of an epigraph as a one-stanza poem.
+func epigraphVerse(b ast.Epigraph) ast.VerseBlock {
+	return ast.VerseBlock{Stanzas: []ast.Stanza{{Lines: b.Lines}}}
+}
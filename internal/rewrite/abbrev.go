@@ -0,0 +1,271 @@
+package rewrite
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+	"github.com/n0madic/txtfmt/internal/config"
+)
+
+type abbrBinding int
+
+const (
+	bindNumberBefore abbrBinding = 1 << iota
+	bindWordAfter
+	bindNumberAfter
+)
+
+type abbreviation struct {
+	parts   []string
+	joined  bool
+	binding abbrBinding
+}
+
+var abbreviationsRU = []abbreviation{
+	{parts: []string{"т", "е"}},
+	{parts: []string{"т", "к"}},
+	{parts: []string{"т", "н"}},
+	{parts: []string{"т", "ч"}},
+	{parts: []string{"т", "д"}},
+	{parts: []string{"т", "п"}},
+	{parts: []string{"н", "э"}, binding: bindNumberBefore},
+	{parts: []string{"г"}, binding: bindNumberBefore},
+	{parts: []string{"гг"}, binding: bindNumberBefore},
+	{parts: []string{"в"}, binding: bindNumberBefore},
+	{parts: []string{"вв"}, binding: bindNumberBefore},
+	{parts: []string{"тыс"}, binding: bindNumberBefore},
+	{parts: []string{"млн"}, binding: bindNumberBefore},
+	{parts: []string{"млрд"}, binding: bindNumberBefore},
+	{parts: []string{"руб"}, binding: bindNumberBefore},
+	{parts: []string{"коп"}, binding: bindNumberBefore},
+	{parts: []string{"им"}, binding: bindWordAfter},
+	{parts: []string{"ул"}, binding: bindWordAfter},
+	{parts: []string{"см"}, binding: bindWordAfter},
+	{parts: []string{"проф"}, binding: bindWordAfter},
+	{parts: []string{"акад"}, binding: bindWordAfter},
+	{parts: []string{"стр"}, binding: bindNumberAfter},
+	{parts: []string{"рис"}, binding: bindNumberAfter},
+	{parts: []string{"гл"}, binding: bindNumberAfter},
+	{parts: []string{"др"}},
+	{parts: []string{"напр"}},
+}
+
+var abbreviationsUA = []abbreviation{
+	{parts: []string{"т", "з"}},
+	{parts: []string{"т", "зв"}},
+	{parts: []string{"т", "д"}},
+	{parts: []string{"т", "ін"}},
+	{parts: []string{"н", "е"}, binding: bindNumberBefore},
+	{parts: []string{"р"}, binding: bindNumberBefore},
+	{parts: []string{"рр"}, binding: bindNumberBefore},
+	{parts: []string{"ст"}, binding: bindNumberBefore},
+	{parts: []string{"тис"}, binding: bindNumberBefore},
+	{parts: []string{"млн"}, binding: bindNumberBefore},
+	{parts: []string{"млрд"}, binding: bindNumberBefore},
+	{parts: []string{"грн"}, binding: bindNumberBefore},
+	{parts: []string{"ім"}, binding: bindWordAfter},
+	{parts: []string{"вул"}, binding: bindWordAfter},
+	{parts: []string{"див"}, binding: bindWordAfter},
+	{parts: []string{"проф"}, binding: bindWordAfter},
+	{parts: []string{"акад"}, binding: bindWordAfter},
+	{parts: []string{"стор"}, binding: bindNumberAfter},
+	{parts: []string{"рис"}, binding: bindNumberAfter},
+	{parts: []string{"ін"}},
+	{parts: []string{"напр"}},
+}
+
+var abbreviationsEN = []abbreviation{
+	{parts: []string{"e", "g"}, joined: true},
+	{parts: []string{"i", "e"}, joined: true},
+	{parts: []string{"a", "m"}, joined: true, binding: bindNumberBefore},
+	{parts: []string{"p", "m"}, joined: true, binding: bindNumberBefore},
+	{parts: []string{"mr"}, binding: bindWordAfter},
+	{parts: []string{"mrs"}, binding: bindWordAfter},
+	{parts: []string{"ms"}, binding: bindWordAfter},
+	{parts: []string{"dr"}, binding: bindWordAfter},
+	{parts: []string{"st"}, binding: bindWordAfter},
+	{parts: []string{"prof"}, binding: bindWordAfter},
+	{parts: []string{"vs"}, binding: bindWordAfter},
+	{parts: []string{"no"}, binding: bindNumberAfter},
+	{parts: []string{"p"}, binding: bindNumberAfter},
+	{parts: []string{"etc"}},
+}
+
+func abbreviationsForLang(lang config.Lang) []abbreviation {
+	switch lang {
+	case config.LangRU:
+		return abbreviationsRU
+	case config.LangUA:
+		return abbreviationsUA
+	case config.LangEN:
+		return abbreviationsEN
+	default:
+		return nil
+	}
+}
+
+func normalizeAbbreviationsDocument(doc *ast.Document, lang config.Lang) {
+	dict := abbreviationsForLang(lang)
+	if len(dict) == 0 {
+		return
+	}
+	applyToAllInlines(doc, func(in []ast.Inline) []ast.Inline {
+		return normalizeAbbreviationsList(in, dict)
+	})
+}
+
+func normalizeAbbreviationsList(in []ast.Inline, dict []abbreviation) []ast.Inline {
+	in = normalizeChildren(in, func(children []ast.Inline) []ast.Inline {
+		return normalizeAbbreviationsList(children, dict)
+	})
+
+	out := make([]ast.Inline, 0, len(in))
+	for i := 0; i < len(in); i++ {
+		if abbr, end, ok := matchAbbreviationAt(in, i, dict); ok && abbr.joined {
+			var b strings.Builder
+			for _, item := range in[i:end] {
+				switch it := item.(type) {
+				case ast.Word:
+					b.WriteString(it.S)
+				case ast.Punct:
+					b.WriteRune(it.Ch)
+				}
+			}
+			out = append(out, ast.Word{S: b.String()})
+			i = end - 1
+			continue
+		}
+
+		if num, suffix, ok := splitNumberAbbreviation(in, i, dict); ok {
+			out = append(out, ast.Word{S: num}, ast.Space{Kind: ast.SpaceNormal}, ast.Word{S: suffix})
+			continue
+		}
+		out = append(out, in[i])
+	}
+	return out
+}
+
+// matchAbbreviationAt matches dictionary entries written as "т.е.", "т. е." or
+// an already joined word like "e.g." starting at idx and returns the end index.
+func matchAbbreviationAt(in []ast.Inline, idx int, dict []abbreviation) (abbreviation, int, bool) {
+	w, ok := in[idx].(ast.Word)
+	if !ok {
+		return abbreviation{}, 0, false
+	}
+	for _, abbr := range dict {
+		if abbr.joined && strings.EqualFold(w.S, strings.Join(abbr.parts, ".")+".") {
+			return abbr, idx + 1, true
+		}
+	}
+
+	for _, abbr := range dict {
+		if end, ok := matchAbbreviationParts(in, idx, abbr.parts); ok {
+			return abbr, end, true
+		}
+	}
+	return abbreviation{}, 0, false
+}
+
+func matchAbbreviationParts(in []ast.Inline, idx int, parts []string) (int, bool) {
+	i := idx
+	for n, part := range parts {
+		if n > 0 {
+			for i < len(in) && isSpace(in[i]) {
+				i++
+			}
+		}
+		if i+1 >= len(in) {
+			return 0, false
+		}
+		w, ok := in[i].(ast.Word)
+		if !ok || strings.ToLower(w.S) != part {
+			return 0, false
+		}
+		p, ok := in[i+1].(ast.Punct)
+		if !ok || p.Ch != '.' {
+			return 0, false
+		}
+		i += 2
+	}
+	return i, true
+}
+
+func splitNumberAbbreviation(in []ast.Inline, idx int, dict []abbreviation) (string, string, bool) {
+	w, ok := in[idx].(ast.Word)
+	if !ok || idx+1 >= len(in) {
+		return "", "", false
+	}
+	if p, ok := in[idx+1].(ast.Punct); !ok || p.Ch != '.' {
+		return "", "", false
+	}
+
+	runes := []rune(w.S)
+	cut := 0
+	for cut < len(runes) && unicode.IsDigit(runes[cut]) {
+		cut++
+	}
+	if cut == 0 || cut == len(runes) {
+		return "", "", false
+	}
+	suffix := string(runes[cut:])
+	for _, abbr := range dict {
+		if len(abbr.parts) == 1 && abbr.binding&bindNumberBefore != 0 && strings.ToLower(suffix) == abbr.parts[0] {
+			return string(runes[:cut]), suffix, true
+		}
+	}
+	return "", "", false
+}
+
+// abbreviationBindings returns indexes of spaces that must not break around
+// dictionary abbreviations: inside "т. е.", in "2020 г." and in "им. Пушкина".
+func abbreviationBindings(in []ast.Inline, lang config.Lang) map[int]struct{} {
+	dict := abbreviationsForLang(lang)
+	bound := make(map[int]struct{})
+	for i := 0; i < len(in); i++ {
+		abbr, end, ok := matchAbbreviationAt(in, i, dict)
+		if !ok {
+			continue
+		}
+		for j := i; j < end; j++ {
+			if isSpace(in[j]) {
+				bound[j] = struct{}{}
+			}
+		}
+		if abbr.binding&bindNumberBefore != 0 && i >= 2 && isSpace(in[i-1]) && isNumberLike(in[i-2]) {
+			bound[i-1] = struct{}{}
+		}
+		if end+1 < len(in) && isSpace(in[end]) {
+			next := in[end+1]
+			if (abbr.binding&bindWordAfter != 0 && isWordInline(next)) ||
+				(abbr.binding&bindNumberAfter != 0 && isNumericWordInline(next)) {
+				bound[end] = struct{}{}
+			}
+		}
+		i = end - 1
+	}
+	return bound
+}
+
+func isNumberLike(in ast.Inline) bool {
+	if isNumericWordInline(in) {
+		return true
+	}
+	w, ok := in.(ast.Word)
+	if !ok || w.S == "" {
+		return false
+	}
+	for _, r := range w.S {
+		switch r {
+		case 'I', 'V', 'X', 'L', 'C', 'D', 'M':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func isWordInline(in ast.Inline) bool {
+	_, ok := in.(ast.Word)
+	return ok
+}
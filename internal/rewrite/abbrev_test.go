@@ -0,0 +1,69 @@
+package rewrite
+
+import (
+	"testing"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+	"github.com/n0madic/txtfmt/internal/config"
+)
+
+func TestAbbreviationNormalization(t *testing.T) {
+	t.Run("en joined", func(t *testing.T) {
+		in := []ast.Inline{
+			ast.Word{S: "e"}, ast.Punct{Ch: '.'}, ast.Space{Kind: ast.SpaceNormal},
+			ast.Word{S: "g"}, ast.Punct{Ch: '.'}, ast.Space{Kind: ast.SpaceNormal},
+			ast.Word{S: "this"},
+		}
+		out := normalizeAbbreviationsList(in, abbreviationsEN)
+		if w, ok := out[0].(ast.Word); !ok || w.S != "e.g." {
+			t.Fatalf("expected joined e.g., got %#v", out[0])
+		}
+	})
+
+	t.Run("ru year split", func(t *testing.T) {
+		in := []ast.Inline{ast.Word{S: "2020г"}, ast.Punct{Ch: '.'}}
+		out := normalizeAbbreviationsList(in, abbreviationsRU)
+		if len(out) != 4 {
+			t.Fatalf("expected number, space, suffix and dot, got %#v", out)
+		}
+		if w, ok := out[0].(ast.Word); !ok || w.S != "2020" {
+			t.Fatalf("expected 2020, got %#v", out[0])
+		}
+		if w, ok := out[2].(ast.Word); !ok || w.S != "г" {
+			t.Fatalf("expected г, got %#v", out[2])
+		}
+	})
+}
+
+func TestAbbreviationNBSP(t *testing.T) {
+	t.Run("ru multi-part", func(t *testing.T) {
+		in := []ast.Inline{
+			ast.Word{S: "т"}, ast.Punct{Ch: '.'}, ast.Space{Kind: ast.SpaceNormal},
+			ast.Word{S: "е"}, ast.Punct{Ch: '.'},
+		}
+		out := applyNBSPList(in, config.LangRU)
+		if sp, ok := out[2].(ast.Space); !ok || sp.Kind != ast.SpaceNBSP {
+			t.Fatalf("expected NBSP inside abbreviation, got %#v", out[2])
+		}
+	})
+
+	t.Run("ru year", func(t *testing.T) {
+		in := []ast.Inline{
+			ast.Word{S: "2020"}, ast.Space{Kind: ast.SpaceNormal}, ast.Word{S: "г"}, ast.Punct{Ch: '.'},
+		}
+		out := applyNBSPList(in, config.LangRU)
+		if sp, ok := out[1].(ast.Space); !ok || sp.Kind != ast.SpaceNBSP {
+			t.Fatalf("expected NBSP before year abbreviation, got %#v", out[1])
+		}
+	})
+
+	t.Run("page abbreviation", func(t *testing.T) {
+		in := []ast.Inline{
+			ast.Word{S: "стр"}, ast.Punct{Ch: '.'}, ast.Space{Kind: ast.SpaceNormal}, ast.Word{S: "5"},
+		}
+		out := applyNBSPList(in, config.LangRU)
+		if sp, ok := out[2].(ast.Space); !ok || sp.Kind != ast.SpaceNBSP {
+			t.Fatalf("expected NBSP after page abbreviation, got %#v", out[2])
+		}
+	})
+}
@@ -18,7 +18,7 @@ func classifyDashesList(in []ast.Inline) []ast.Inline {
 
 	for i := range out {
 		d, ok := out[i].(ast.Dash)
-		if !ok {
+		if !ok || d.Typewriter {
 			continue
 		}
 
@@ -46,7 +46,7 @@ func classifyDashesList(in []ast.Inline) []ast.Inline {
 
 func isWordLike(in ast.Inline) bool {
 	switch in.(type) {
-	case ast.Word, ast.QuoteSpan, ast.ParenSpan:
+	case ast.Word, ast.QuoteSpan, ast.ParenSpan, ast.Emphasis, ast.Verbatim, ast.FootnoteRef:
 		return true
 	default:
 		return false
@@ -25,6 +25,17 @@ func TestDashClassification(t *testing.T) {
 		}
 	})
 
+	t.Run("typewriter dash", func(t *testing.T) {
+		for _, left := range []string{"Слово", "1990"} {
+			in := []ast.Inline{ast.Word{S: left}, ast.Dash{Kind: ast.DashEmDash, Typewriter: true}, ast.Word{S: "2000"}}
+			out := classifyDashesList(in)
+			d, ok := out[1].(ast.Dash)
+			if !ok || d.Kind != ast.DashEmDash {
+				t.Fatalf("expected EmDash after %q, got %#v", left, out[1])
+			}
+		}
+	})
+
 	t.Run("spaced dash", func(t *testing.T) {
 		in := []ast.Inline{
 			ast.Word{S: "слово"}, ast.Space{Kind: ast.SpaceNormal}, ast.Dash{Kind: ast.DashHyphen}, ast.Space{Kind: ast.SpaceNormal}, ast.Word{S: "слово"},
@@ -53,6 +53,25 @@ func normalizeDialogueTurn(in []ast.Inline) ([]ast.Inline, bool) {
 	return out, false
 }
 
+// normalizeSpeakerTurns drops the dialogue dash some transcripts put after
+// the speaker label: the label already marks the speech.
+func normalizeSpeakerTurns(doc *ast.Document) {
+	for i, blk := range doc.Blocks {
+		st, ok := blk.(ast.SpeakerTurn)
+		if !ok {
+			continue
+		}
+		in := trimLeadingSpaces(st.In)
+		if len(in) > 1 && isEmDash(in[0]) {
+			in = trimLeadingSpaces(in[1:])
+		}
+		if len(in) > 0 {
+			st.In = in
+		}
+		doc.Blocks[i] = st
+	}
+}
+
 func trimLeadingSpaces(in []ast.Inline) []ast.Inline {
 	idx := 0
 	for idx < len(in) {
@@ -64,3 +83,62 @@ func trimLeadingSpaces(in []ast.Inline) []ast.Inline {
 	}
 	return in[idx:]
 }
+
+// splitInlineDialogueDocument moves speech that follows a colon
+// (`Он сказал: — Привет!`) out of the paragraph into a dialogue turn.
+func splitInlineDialogueDocument(doc *ast.Document) {
+	out := make([]ast.Block, 0, len(doc.Blocks))
+	split := false
+	for _, blk := range doc.Blocks {
+		// The dialogue that follows the split speech continues its block.
+		if db, ok := blk.(ast.DialogueBlock); ok && split {
+			prev := out[len(out)-1].(ast.DialogueBlock)
+			prev.Turns = append(prev.Turns, db.Turns...)
+			out[len(out)-1] = prev
+			split = false
+			continue
+		}
+		split = false
+		p, ok := blk.(ast.Paragraph)
+		if !ok {
+			out = append(out, blk)
+			continue
+		}
+		idx := inlineDialogueStart(p.In)
+		if idx < 0 {
+			out = append(out, blk)
+			continue
+		}
+		split = true
+		out = append(out,
+			ast.Paragraph{In: trimTrailingSpaces(p.In[:idx])},
+			ast.DialogueBlock{Turns: []ast.DialogueTurn{{In: p.In[idx:]}}},
+		)
+	}
+	doc.Blocks = out
+}
+
+// inlineDialogueStart returns the index of the dash that opens speech after
+// a colon, or -1. The speech must start with a capital letter.
+func inlineDialogueStart(in []ast.Inline) int {
+	for i := 1; i < len(in); i++ {
+		if !isEmDash(in[i]) {
+			continue
+		}
+		p := prevNonSpaceIndex(in, i)
+		if p < 0 {
+			continue
+		}
+		if colon, ok := in[p].(ast.Punct); !ok || colon.Ch != ':' {
+			continue
+		}
+		n := nextNonSpaceIndex(in, i)
+		if n < 0 {
+			continue
+		}
+		if w, ok := in[n].(ast.Word); ok && !startsLower(w.S) && p > 0 {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,264 @@
+package rewrite
+
+import (
+	"github.com/n0madic/txtfmt/internal/ast"
+	"github.com/n0madic/txtfmt/internal/config"
+)
+
+// convertDialogueStyle switches direct speech between quoted paragraphs
+// (`«Привет», — сказал он.`) and dash dialogue (`— Привет, — сказал он.`).
+func convertDialogueStyle(doc *ast.Document, style config.DialogueStyle, lang config.Lang) {
+	switch style {
+	case config.DialogueStyleDash:
+		quotesToDialogue(doc)
+	case config.DialogueStyleQuotes:
+		dialogueToQuotes(doc, lang)
+	}
+}
+
+// quotesToDialogue turns paragraphs that open with quoted speech into
+// dialogue turns; neighbouring turns share one dialogue block.
+func quotesToDialogue(doc *ast.Document) {
+	out := make([]ast.Block, 0, len(doc.Blocks))
+	for _, blk := range doc.Blocks {
+		p, ok := blk.(ast.Paragraph)
+		if !ok {
+			out = append(out, blk)
+			continue
+		}
+		turn, ok := quotedSpeechTurn(p.In)
+		if !ok {
+			out = append(out, blk)
+			continue
+		}
+		if last := len(out) - 1; last >= 0 {
+			if db, ok := out[last].(ast.DialogueBlock); ok {
+				db.Turns = append(db.Turns, turn)
+				out[last] = db
+				continue
+			}
+		}
+		out = append(out, ast.DialogueBlock{Turns: []ast.DialogueTurn{turn}})
+	}
+	doc.Blocks = out
+}
+
+// quotedSpeechTurn rebuilds `«Привет», — сказал он. — «Как дела?»` as
+// `— Привет, — сказал он. — Как дела?`. A quote is speech only when it ends
+// with a mark or is followed by one: `«Война и мир» — роман` stays prose.
+func quotedSpeechTurn(in []ast.Inline) (ast.DialogueTurn, bool) {
+	in = trimLeadingSpaces(in)
+	if len(in) == 0 || !isSpeechQuote(in, 0) {
+		return ast.DialogueTurn{}, false
+	}
+
+	out := []ast.Inline{ast.Dash{Kind: ast.DashEmDash}, ast.Space{Kind: ast.SpaceNormal}}
+	for i := 0; i < len(in); i++ {
+		q, ok := in[i].(ast.QuoteSpan)
+		if !ok || (i > 0 && !opensSpeechAgain(in, i)) {
+			out = append(out, in[i])
+			continue
+		}
+		if i > 0 {
+			out = trimTrailingSpaces(out)
+			if len(out) > 0 && isEmDash(out[len(out)-1]) {
+				out = trimTrailingSpaces(out[:len(out)-1])
+			}
+			out = append(out, ast.Space{Kind: ast.SpaceNormal}, ast.Dash{Kind: ast.DashEmDash}, ast.Space{Kind: ast.SpaceNormal})
+		}
+		out = append(out, trimTrailingSpaces(trimLeadingSpaces(q.In))...)
+
+		// «Привет», — сказал он: the comma moves into the speech.
+		if i+1 < len(in) {
+			if p, ok := in[i+1].(ast.Punct); ok && !endsWithMark(q.In) {
+				out = append(out, p)
+				i++
+			}
+		}
+		n := nextNonSpaceIndex(in, i)
+		if n < 0 {
+			break
+		}
+		if _, ok := in[n].(ast.Dash); ok {
+			i = n
+		}
+		out = append(out, ast.Space{Kind: ast.SpaceNormal}, ast.Dash{Kind: ast.DashEmDash}, ast.Space{Kind: ast.SpaceNormal})
+		i = nextNonSpaceIndex(in, i) - 1
+		if i < 0 {
+			break
+		}
+	}
+	return ast.DialogueTurn{In: out}, true
+}
+
+func isSpeechQuote(in []ast.Inline, idx int) bool {
+	q, ok := in[idx].(ast.QuoteSpan)
+	if !ok || len(q.In) == 0 {
+		return false
+	}
+	if endsWithMark(q.In) {
+		return true
+	}
+	if idx+1 >= len(in) {
+		return false
+	}
+	p, ok := in[idx+1].(ast.Punct)
+	if !ok {
+		return false
+	}
+	switch p.Ch {
+	case '.', '!', '?':
+		return true
+	case ',':
+		n := nextNonSpaceIndex(in, idx+1)
+		return n >= 0 && isEmDash(in[n])
+	}
+	return false
+}
+
+// opensSpeechAgain tells a quote that resumes speech after a remark
+// (`— сказал он. — «Как дела?»`) from a quotation inside the remark.
+func opensSpeechAgain(in []ast.Inline, idx int) bool {
+	if !isSpeechQuote(in, idx) {
+		return false
+	}
+	p := prevNonSpaceIndex(in, idx)
+	if p < 0 {
+		return false
+	}
+	switch prev := in[p].(type) {
+	case ast.Dash:
+		return true
+	case ast.Punct:
+		return prev.Ch == '.' || prev.Ch == ','
+	}
+	return false
+}
+
+// dialogueToQuotes renders each dialogue turn as a paragraph with the
+// speech in the language's outer quotes.
+func dialogueToQuotes(doc *ast.Document, lang config.Lang) {
+	out := make([]ast.Block, 0, len(doc.Blocks))
+	for _, blk := range doc.Blocks {
+		db, ok := blk.(ast.DialogueBlock)
+		if !ok {
+			out = append(out, blk)
+			continue
+		}
+		for _, turn := range db.Turns {
+			in := quotedTurn(turn.In, lang)
+			if len(in) == 0 {
+				continue
+			}
+			out = append(out, ast.Paragraph{In: in})
+		}
+	}
+	doc.Blocks = out
+}
+
+// quotedTurn splits a turn at its remark dashes. English quotes every piece
+// of speech and drops the dashes: `"Hello," he said. "How are you?"`.
+// Russian and Ukrainian keep the dashes; a remark inside speech stays
+// inside one pair of quotes: `«Привет, — сказал он. — Как дела?»`.
+func quotedTurn(in []ast.Inline, lang config.Lang) []ast.Inline {
+	in = trimLeadingSpaces(in)
+	if len(in) > 0 {
+		if _, ok := in[0].(ast.Dash); ok {
+			in = trimLeadingSpaces(in[1:])
+		}
+	}
+	in = trimTrailingSpaces(in)
+	if len(in) == 0 {
+		return nil
+	}
+
+	var parts [][]ast.Inline
+	start := 0
+	for i := 1; i < len(in); i++ {
+		if isEmDash(in[i]) && isSpace(in[i-1]) {
+			parts = append(parts, trimTrailingSpaces(in[start:i]))
+			start = i + 1
+		}
+	}
+	parts = append(parts, trimLeadingSpaces(in[start:]))
+
+	if lang == config.LangEN {
+		var out []ast.Inline
+		for k, part := range parts {
+			if len(out) > 0 {
+				out = append(out, ast.Space{Kind: ast.SpaceNormal})
+			}
+			if k%2 == 0 {
+				out = append(out, quoteSpeech(part))
+				continue
+			}
+			out = append(out, part...)
+		}
+		return out
+	}
+
+	dash := []ast.Inline{ast.Space{Kind: ast.SpaceNormal}, ast.Dash{Kind: ast.DashEmDash}, ast.Space{Kind: ast.SpaceNormal}}
+	switch len(parts) {
+	case 1:
+		speech, mark := splitOuterMark(parts[0], '.')
+		return append([]ast.Inline{quoteSpeech(speech)}, mark...)
+	case 2:
+		speech, mark := splitOuterMark(parts[0], ',', '.')
+		if len(mark) > 0 {
+			mark = []ast.Inline{ast.Punct{Ch: ','}}
+		}
+		out := append([]ast.Inline{quoteSpeech(speech)}, mark...)
+		out = append(out, dash...)
+		return append(out, parts[1]...)
+	default:
+		speech, mark := splitOuterMark(in, '.')
+		return append([]ast.Inline{quoteSpeech(speech)}, mark...)
+	}
+}
+
+// splitOuterMark cuts a trailing mark that Russian puts after the closing
+// quote: «Привет». and «Привет», — сказал он.
+func splitOuterMark(in []ast.Inline, marks ...rune) ([]ast.Inline, []ast.Inline) {
+	if len(in) == 0 {
+		return in, nil
+	}
+	p, ok := in[len(in)-1].(ast.Punct)
+	if !ok {
+		return in, nil
+	}
+	for _, m := range marks {
+		if p.Ch == m {
+			return in[:len(in)-1], []ast.Inline{p}
+		}
+	}
+	return in, nil
+}
+
+func quoteSpeech(in []ast.Inline) ast.QuoteSpan {
+	return ast.QuoteSpan{Level: ast.QuotePrimary, In: append([]ast.Inline(nil), in...)}
+}
+
+func endsWithMark(in []ast.Inline) bool {
+	in = trimTrailingSpaces(in)
+	if len(in) == 0 {
+		return false
+	}
+	switch it := in[len(in)-1].(type) {
+	case ast.Ellipsis:
+		return true
+	case ast.Punct:
+		switch it.Ch {
+		case ',', '.', '!', '?':
+			return true
+		}
+	}
+	return false
+}
+
+func trimTrailingSpaces(in []ast.Inline) []ast.Inline {
+	end := len(in)
+	for end > 0 && isSpace(in[end-1]) {
+		end--
+	}
+	return in[:end]
+}
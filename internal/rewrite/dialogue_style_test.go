@@ -0,0 +1,59 @@
+package rewrite_test
+
+import (
+	"testing"
+
+	"github.com/n0madic/txtfmt/internal/config"
+)
+
+func TestDialogueStyleConversion(t *testing.T) {
+	tests := []struct {
+		name  string
+		lang  string
+		style config.DialogueStyle
+		in    string
+		want  string
+	}{
+		{
+			name:  "quotes to dash",
+			lang:  "ru",
+			style: config.DialogueStyleDash,
+			in:    "«Привет», — сказал он.\n\n«Как дела?» — спросила она. «Всё хорошо?»",
+			want:  "— Привет, — сказал он.\n— Как дела? — спросила она. — Всё хорошо?",
+		},
+		{
+			name:  "quoted title stays prose",
+			lang:  "ru",
+			style: config.DialogueStyleDash,
+			in:    "«Война и мир» — роман Толстого.",
+			want:  "«Война и мир» — роман Толстого.",
+		},
+		{
+			name:  "dash to quotes ru",
+			lang:  "ru",
+			style: config.DialogueStyleQuotes,
+			in:    "— Привет, — сказал он.\n— Хорошо.\n— Ну и ладно! — махнул он рукой.\n— Стой, — сказал он. — Куда ты?",
+			want:  "«Привет», — сказал он.\n\n«Хорошо».\n\n«Ну и ладно!» — махнул он рукой.\n\n«Стой, — сказал он. — Куда ты?»",
+		},
+		{
+			name:  "dash to quotes en",
+			lang:  "en",
+			style: config.DialogueStyleQuotes,
+			in:    "— Hello, — he said. — How are you?\n— Fine.",
+			want:  "“Hello,” he said. “How are you?”\n\n“Fine.”",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := config.New(tc.lang, "", false)
+			if err != nil {
+				t.Fatalf("config: %v", err)
+			}
+			cfg.DialogueStyle = tc.style
+			if got := formatText(tc.in, cfg); got != tc.want {
+				t.Fatalf("unexpected output\nexpected: %q\nactual:   %q", tc.want, got)
+			}
+		})
+	}
+}
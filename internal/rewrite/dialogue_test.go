@@ -0,0 +1,41 @@
+package rewrite_test
+
+import (
+	"testing"
+
+	"github.com/n0madic/txtfmt/internal/config"
+)
+
+func TestInlineDialogueAfterColon(t *testing.T) {
+	cfg, err := config.New("ua", "", false)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		split bool
+		in    string
+		want  string
+	}{
+		{name: "spaced hyphen", in: "Він спитав: - Куди?", want: "Він спитав: — Куди?"},
+		{name: "attached hyphen", in: "Він спитав: -Куди?", want: "Він спитав: — Куди?"},
+		{name: "double hyphen", in: "Він спитав: -- Куди?", want: "Він спитав: — Куди?"},
+		{
+			name:  "split into turn",
+			split: true,
+			in:    "Він обернувся і спитав: - Куди?\n- Додому.",
+			want:  "Він обернувся і спитав:\n\n— Куди?\n— Додому.",
+		},
+		{name: "colon before lowercase stays", split: true, in: "Час: - пізно.", want: "Час: — пізно."},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg.SplitInlineDialogue = tc.split
+			if got := formatText(tc.in, cfg); got != tc.want {
+				t.Fatalf("unexpected output\nexpected: %q\nactual:   %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -13,7 +13,7 @@ import (
 )
 
 func TestGoldenCasesRU(t *testing.T) {
-	cases := []string{"dialogue", "dashes", "quotes", "nested_quotes", "ellipsis", "spacing", "indented_paragraphs", "contents_meta", "contents_chapters"}
+	cases := []string{"dialogue", "dashes", "quotes", "nested_quotes", "ellipsis", "spacing", "indented_paragraphs", "contents_meta", "contents_chapters", "remarks"}
 	cfg, err := config.New("ru", "", false)
 	if err != nil {
 		t.Fatalf("config: %v", err)
@@ -0,0 +1,145 @@
+package rewrite
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+	"github.com/n0madic/txtfmt/internal/config"
+)
+
+var oneLetterWords = map[config.Lang]string{
+	config.LangRU: "АВИКОСУЯ",
+	config.LangUA: "АВЗІЙОУЯ",
+	config.LangEN: "AI",
+}
+
+type initialsChain struct {
+	start  int
+	end    int
+	spaces []int
+}
+
+// initialsBindings finds initials chains such as "А. С. Пушкин", "Пушкин А. С."
+// and "J. R. R. Tolkien" and returns the kind of every space that binds them.
+func initialsBindings(in []ast.Inline, lang config.Lang, between ast.SpaceKind) map[int]ast.SpaceKind {
+	bound := make(map[int]ast.SpaceKind)
+	for i := 0; i < len(in); i++ {
+		chain, ok := initialsChainAt(in, i)
+		if !ok {
+			continue
+		}
+		i = chain.end - 1
+
+		before := surnameBeforeChain(in, chain)
+		after := surnameAfterChain(in, chain)
+		if after >= 0 && before >= 0 && !isSentenceInitialWord(in, prevNonSpaceIndex(in, chain.start)) {
+			after = -1
+		}
+		if len(chain.spaces) == 0 && after >= 0 && isSentenceEndingLetter(in, chain, lang) {
+			after = -1
+		}
+		if before < 0 && after < 0 && len(chain.spaces) == 0 {
+			continue
+		}
+
+		for _, sp := range chain.spaces {
+			bound[sp] = between
+		}
+		switch {
+		case after >= 0:
+			bound[after] = ast.SpaceNBSP
+		case before >= 0:
+			bound[before] = ast.SpaceNBSP
+		}
+	}
+	return bound
+}
+
+func initialsChainAt(in []ast.Inline, idx int) (initialsChain, bool) {
+	if !isInitialStartingAt(in, idx) {
+		return initialsChain{}, false
+	}
+	chain := initialsChain{start: idx, end: idx + 2}
+	for {
+		next := chain.end
+		if next < len(in) && isSpace(in[next]) && isInitialStartingAt(in, next+1) {
+			chain.spaces = append(chain.spaces, next)
+			chain.end = next + 3
+			continue
+		}
+		if isInitialStartingAt(in, next) {
+			chain.end = next + 2
+			continue
+		}
+		return chain, true
+	}
+}
+
+func surnameAfterChain(in []ast.Inline, chain initialsChain) int {
+	if chain.end+1 >= len(in) || !isSpace(in[chain.end]) {
+		return -1
+	}
+	if !isSurnameWord(in[chain.end+1]) {
+		return -1
+	}
+	return chain.end
+}
+
+func surnameBeforeChain(in []ast.Inline, chain initialsChain) int {
+	if chain.start < 2 || !isSpace(in[chain.start-1]) {
+		return -1
+	}
+	if !isSurnameWord(in[chain.start-2]) {
+		return -1
+	}
+	return chain.start - 1
+}
+
+func isSurnameWord(in ast.Inline) bool {
+	w, ok := in.(ast.Word)
+	if !ok || utf8.RuneCountInString(w.S) < 2 {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(w.S)
+	return unicode.IsUpper(r)
+}
+
+// isSentenceInitialWord reports whether the word at idx opens a sentence, so
+// its capital letter says nothing about it being a surname.
+func isSentenceInitialWord(in []ast.Inline, idx int) bool {
+	if idx < 0 {
+		return true
+	}
+	prev := prevNonSpaceIndex(in, idx)
+	if prev < 0 {
+		return true
+	}
+	switch it := in[prev].(type) {
+	case ast.Punct:
+		return it.Ch == '.' || it.Ch == '!' || it.Ch == '?'
+	case ast.Ellipsis, ast.Dash:
+		return true
+	}
+	return false
+}
+
+// isSentenceEndingLetter detects "из пункта А. Затем": a lone capital that is
+// also a one-letter word, preceded by lowercase text, ends the sentence.
+func isSentenceEndingLetter(in []ast.Inline, chain initialsChain, lang config.Lang) bool {
+	w := in[chain.start].(ast.Word)
+	if !strings.Contains(oneLetterWords[lang], w.S) {
+		return false
+	}
+	prev := prevNonSpaceIndex(in, chain.start)
+	if prev < 0 {
+		return false
+	}
+	pw, ok := in[prev].(ast.Word)
+	if !ok {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(pw.S)
+	return unicode.IsLower(r)
+}
@@ -0,0 +1,57 @@
+package rewrite
+
+import (
+	"testing"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+	"github.com/n0madic/txtfmt/internal/config"
+)
+
+func TestInitialsBindings(t *testing.T) {
+	sp := ast.Space{Kind: ast.SpaceNormal}
+
+	t.Run("surname before initials", func(t *testing.T) {
+		in := []ast.Inline{
+			ast.Word{S: "Пушкин"}, sp,
+			ast.Word{S: "А"}, ast.Punct{Ch: '.'}, sp,
+			ast.Word{S: "С"}, ast.Punct{Ch: '.'}, sp,
+			ast.Word{S: "написал"},
+		}
+		out := applyNBSPList(in, config.LangRU)
+		for _, idx := range []int{1, 4} {
+			if s, ok := out[idx].(ast.Space); !ok || s.Kind != ast.SpaceNBSP {
+				t.Fatalf("expected NBSP at %d, got %#v", idx, out[idx])
+			}
+		}
+		if s, ok := out[7].(ast.Space); !ok || s.Kind != ast.SpaceNormal {
+			t.Fatalf("expected normal space before lowercase word, got %#v", out[7])
+		}
+	})
+
+	t.Run("sentence ending letter", func(t *testing.T) {
+		in := []ast.Inline{
+			ast.Word{S: "пункта"}, sp,
+			ast.Word{S: "А"}, ast.Punct{Ch: '.'}, sp,
+			ast.Word{S: "Затем"},
+		}
+		out := applyNBSPList(in, config.LangRU)
+		if s, ok := out[4].(ast.Space); !ok || s.Kind != ast.SpaceNormal {
+			t.Fatalf("expected sentence break to stay breakable, got %#v", out[4])
+		}
+	})
+
+	t.Run("thin space between initials", func(t *testing.T) {
+		in := []ast.Inline{
+			ast.Word{S: "J"}, ast.Punct{Ch: '.'}, sp,
+			ast.Word{S: "R"}, ast.Punct{Ch: '.'}, sp,
+			ast.Word{S: "Tolkien"},
+		}
+		out := applyNBSPListWith(in, config.LangEN, ast.SpaceThin)
+		if s, ok := out[2].(ast.Space); !ok || s.Kind != ast.SpaceThin {
+			t.Fatalf("expected thin space between initials, got %#v", out[2])
+		}
+		if s, ok := out[5].(ast.Space); !ok || s.Kind != ast.SpaceNBSP {
+			t.Fatalf("expected NBSP before surname, got %#v", out[5])
+		}
+	})
+}
@@ -3,7 +3,6 @@ package rewrite
 import (
 	"strings"
 	"unicode"
-	"unicode/utf8"
 
 	"github.com/n0madic/txtfmt/internal/ast"
 	"github.com/n0madic/txtfmt/internal/config"
@@ -30,30 +29,56 @@ var shortWordsUA = map[string]struct{}{
 
 func applyNBSPDocument(doc *ast.Document, cfg config.Config) {
 	applyToAllInlines(doc, func(in []ast.Inline) []ast.Inline {
-		return applyNBSPList(in, cfg.Lang)
+		return applyNBSPListWith(in, cfg.Lang, initialsSpaceKind(cfg.InitialsSpace))
 	})
 }
 
+func initialsSpaceKind(s config.InitialsSpace) ast.SpaceKind {
+	if s == config.InitialsSpaceThin {
+		return ast.SpaceThin
+	}
+	return ast.SpaceNBSP
+}
+
 func applyNBSPList(in []ast.Inline, lang config.Lang) []ast.Inline {
+	return applyNBSPListWith(in, lang, ast.SpaceNBSP)
+}
+
+func applyNBSPListWith(in []ast.Inline, lang config.Lang, initials ast.SpaceKind) []ast.Inline {
 	out := make([]ast.Inline, 0, len(in))
 	for _, item := range in {
 		switch it := item.(type) {
 		case ast.QuoteSpan:
-			it.In = applyNBSPList(it.In, lang)
+			it.In = applyNBSPListWith(it.In, lang, initials)
 			out = append(out, it)
 		case ast.ParenSpan:
-			it.In = applyNBSPList(it.In, lang)
+			it.In = applyNBSPListWith(it.In, lang, initials)
+			out = append(out, it)
+		case ast.Emphasis:
+			it.In = applyNBSPListWith(it.In, lang, initials)
 			out = append(out, it)
 		default:
 			out = append(out, item)
 		}
 	}
 
+	bound := abbreviationBindings(out, lang)
+	initialsBound := initialsBindings(out, lang, initials)
 	for i := range out {
 		sp, ok := out[i].(ast.Space)
 		if !ok || sp.Kind != ast.SpaceNormal {
 			continue
 		}
+		if _, ok := bound[i]; ok {
+			sp.Kind = ast.SpaceNBSP
+			out[i] = sp
+			continue
+		}
+		if kind, ok := initialsBound[i]; ok {
+			sp.Kind = kind
+			out[i] = sp
+			continue
+		}
 
 		prevIdx := prevNonSpaceIndex(out, i)
 		nextIdx := nextNonSpaceIndex(out, i)
@@ -62,9 +87,7 @@ func applyNBSPList(in []ast.Inline, lang config.Lang) []ast.Inline {
 		}
 
 		if shouldNBSPShortWord(out[prevIdx], out[nextIdx], lang) ||
-			shouldNBSPNumero(out, prevIdx, nextIdx) ||
-			shouldNBSPPageAbbr(out, prevIdx, nextIdx) ||
-			shouldNBSPInitialPattern(out, prevIdx, nextIdx) {
+			shouldNBSPNumero(out, prevIdx, nextIdx) {
 			sp.Kind = ast.SpaceNBSP
 			out[i] = sp
 		}
@@ -123,46 +146,6 @@ func shouldNBSPNumero(in []ast.Inline, prevIdx, nextIdx int) bool {
 	return isNumericWordInline(in[nextIdx])
 }
 
-func shouldNBSPPageAbbr(in []ast.Inline, prevIdx, nextIdx int) bool {
-	p, ok := in[prevIdx].(ast.Punct)
-	if !ok || p.Ch != '.' || prevIdx < 1 {
-		return false
-	}
-	w, ok := in[prevIdx-1].(ast.Word)
-	if !ok {
-		return false
-	}
-	if strings.ToLower(w.S) != "стр" {
-		return false
-	}
-	return isNumericWordInline(in[nextIdx])
-}
-
-func shouldNBSPInitialPattern(in []ast.Inline, prevIdx, nextIdx int) bool {
-	if !isInitialEndingAt(in, prevIdx) {
-		return false
-	}
-	if isInitialStartingAt(in, nextIdx) {
-		return true
-	}
-	if w, ok := in[nextIdx].(ast.Word); ok {
-		return utf8.RuneCountInString(w.S) > 1
-	}
-	return false
-}
-
-func isInitialEndingAt(in []ast.Inline, idx int) bool {
-	p, ok := in[idx].(ast.Punct)
-	if !ok || p.Ch != '.' || idx < 1 {
-		return false
-	}
-	w, ok := in[idx-1].(ast.Word)
-	if !ok {
-		return false
-	}
-	return isUpperSingleLetter(w.S)
-}
-
 func isInitialStartingAt(in []ast.Inline, idx int) bool {
 	w, ok := in[idx].(ast.Word)
 	if !ok || !isUpperSingleLetter(w.S) {
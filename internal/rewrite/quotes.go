@@ -3,33 +3,9 @@ package rewrite
 import "github.com/n0madic/txtfmt/internal/ast"
 
 func emitCanonicalPairsDocument(doc *ast.Document) {
-	for i, blk := range doc.Blocks {
-		switch b := blk.(type) {
-		case ast.TitleBlock:
-			b.In = normalizeQuoteLevels(b.In, 0)
-			doc.Blocks[i] = b
-		case ast.Paragraph:
-			b.In = normalizeQuoteLevels(b.In, 0)
-			doc.Blocks[i] = b
-		case ast.Heading:
-			b.In = normalizeQuoteLevels(b.In, 0)
-			doc.Blocks[i] = b
-		case ast.ContentsBlock:
-			b.In = normalizeQuoteLevels(b.In, 0)
-			for k := range b.Entries {
-				b.Entries[k].In = normalizeQuoteLevels(b.Entries[k].In, 0)
-			}
-			doc.Blocks[i] = b
-		case ast.MetaLineBlock:
-			b.In = normalizeQuoteLevels(b.In, 0)
-			doc.Blocks[i] = b
-		case ast.DialogueBlock:
-			for j := range b.Turns {
-				b.Turns[j].In = normalizeQuoteLevels(b.Turns[j].In, 0)
-			}
-			doc.Blocks[i] = b
-		}
-	}
+	applyToAllInlines(doc, func(in []ast.Inline) []ast.Inline {
+		return normalizeQuoteLevels(in, 0)
+	})
 }
 
 func normalizeQuoteLevels(in []ast.Inline, depth int) []ast.Inline {
@@ -43,6 +19,9 @@ func normalizeQuoteLevels(in []ast.Inline, depth int) []ast.Inline {
 		case ast.ParenSpan:
 			it.In = normalizeQuoteLevels(it.In, depth)
 			out = append(out, it)
+		case ast.Emphasis:
+			it.In = normalizeQuoteLevels(it.In, depth)
+			out = append(out, it)
 		default:
 			out = append(out, item)
 		}
@@ -0,0 +1,176 @@
+package rewrite
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+	"github.com/n0madic/txtfmt/internal/config"
+)
+
+// speechVerbStems start the verbs that usually open an author's remark.
+var speechVerbStems = []string{
+	"сказа", "спроси", "ответи", "крикну", "прошепта", "восклик", "произн", "добави",
+	"замети", "продолжи", "пробормота", "переспроси", "усмехну", "вздохну",
+	"запита", "спита", "відпові", "прошепоті", "вигукну", "дода", "зауважи", "промови",
+}
+
+// normalizeRemarksDocument fixes punctuation around author's remarks in
+// Russian and Ukrainian direct speech: `— Привет, — сказал он. — Как дела?`.
+// Dialogue turns and paragraphs with a speech verb after the dash are
+// checked.
+func normalizeRemarksDocument(doc *ast.Document, lang config.Lang) {
+	if lang != config.LangRU && lang != config.LangUA {
+		return
+	}
+	report := func(problems []string) {
+		for _, p := range problems {
+			doc.Diags = append(doc.Diags, ast.Diag{Code: "DIALOGUE_REMARK_COMMA", Message: p})
+		}
+	}
+	for i, blk := range doc.Blocks {
+		switch b := blk.(type) {
+		case ast.DialogueBlock:
+			for j := range b.Turns {
+				var problems []string
+				b.Turns[j].In, problems = fixRemarkPunctuation(b.Turns[j].In, true)
+				report(problems)
+			}
+			doc.Blocks[i] = b
+		case ast.SpeakerTurn:
+			var problems []string
+			b.In, problems = fixRemarkPunctuation(b.In, true)
+			report(problems)
+			doc.Blocks[i] = b
+		case ast.Paragraph:
+			var problems []string
+			b.In, problems = fixRemarkPunctuation(b.In, false)
+			report(problems)
+			doc.Blocks[i] = b
+		}
+	}
+}
+
+// fixRemarkPunctuation turns a period before a remark dash into a comma and
+// picks the mark after the remark by the case of the speech that follows:
+// a period before a new sentence, a comma before its continuation. A remark
+// with no mark before the dash can't be fixed safely and is reported.
+func fixRemarkPunctuation(in []ast.Inline, dialogue bool) ([]ast.Inline, []string) {
+	var problems []string
+	for i := 1; i < len(in); i++ {
+		if !isEmDash(in[i]) || !isSpace(in[i-1]) {
+			continue
+		}
+		p := prevNonSpaceIndex(in, i)
+		n := nextNonSpaceIndex(in, i)
+		if p < 0 || n < 0 {
+			continue
+		}
+		verb, ok := in[n].(ast.Word)
+		if !ok || !startsLower(verb.S) {
+			continue
+		}
+		speech := isSpeechVerb(verb.S)
+		if !dialogue && !speech {
+			continue
+		}
+
+		switch prev := in[p].(type) {
+		case ast.Punct:
+			if prev.Ch == '.' && !isAbbreviationPeriod(in, p) {
+				in[p] = ast.Punct{Ch: ','}
+			}
+		case ast.QuoteSpan:
+			if speech && !quoteEndsWithMark(prev) {
+				problems = append(problems, fmt.Sprintf("missing comma before author's remark %q", verb.S))
+			}
+		case ast.Word:
+			if speech {
+				problems = append(problems, fmt.Sprintf("missing comma before author's remark %q", verb.S))
+			}
+		}
+
+		fixRemarkEnd(in, n)
+	}
+	return in, problems
+}
+
+// fixRemarkEnd finds the end of the remark that starts at idx, a comma or a
+// period followed by a dash and more speech, and sets the mark by the case
+// of that speech.
+func fixRemarkEnd(in []ast.Inline, idx int) {
+	for k := idx + 1; k < len(in); k++ {
+		switch it := in[k].(type) {
+		case ast.Dash, ast.Ellipsis:
+			return
+		case ast.Punct:
+			if it.Ch != ',' && it.Ch != '.' {
+				return
+			}
+			d := nextNonSpaceIndex(in, k)
+			if d < 0 || !isEmDash(in[d]) {
+				if it.Ch == '.' {
+					return
+				}
+				continue
+			}
+			w := nextNonSpaceIndex(in, d)
+			if w < 0 {
+				return
+			}
+			next, ok := in[w].(ast.Word)
+			if !ok {
+				return
+			}
+			if startsLower(next.S) {
+				in[k] = ast.Punct{Ch: ','}
+			} else {
+				in[k] = ast.Punct{Ch: '.'}
+			}
+			return
+		}
+	}
+}
+
+// quoteEndsWithMark reports quoted speech that already ends with the mark a
+// remark needs: «Хорошо!» — ответила она.
+func quoteEndsWithMark(q ast.QuoteSpan) bool {
+	if len(q.In) == 0 {
+		return false
+	}
+	switch last := q.In[len(q.In)-1].(type) {
+	case ast.Ellipsis:
+		return true
+	case ast.Punct:
+		return last.Ch == '!' || last.Ch == '?' || last.Ch == ','
+	case ast.QuoteSpan:
+		return quoteEndsWithMark(last)
+	}
+	return false
+}
+
+// isAbbreviationPeriod keeps the period of "и т. д." before a dash.
+func isAbbreviationPeriod(in []ast.Inline, p int) bool {
+	if p == 0 {
+		return false
+	}
+	word, ok := in[p-1].(ast.Word)
+	return ok && utf8.RuneCountInString(word.S) <= 2 && startsLower(word.S)
+}
+
+func isSpeechVerb(s string) bool {
+	s = strings.ToLower(s)
+	for _, stem := range speechVerbStems {
+		if strings.HasPrefix(s, stem) {
+			return true
+		}
+	}
+	return false
+}
+
+func startsLower(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsLower(r)
+}
@@ -0,0 +1,75 @@
+package rewrite
+
+import (
+	"testing"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+)
+
+func TestRemarkPunctuation(t *testing.T) {
+	t.Run("period before remark becomes comma", func(t *testing.T) {
+		in := []ast.Inline{
+			ast.Dash{Kind: ast.DashEmDash}, ast.Space{Kind: ast.SpaceNormal},
+			ast.Word{S: "Привет"}, ast.Punct{Ch: '.'}, ast.Space{Kind: ast.SpaceNormal},
+			ast.Dash{Kind: ast.DashEmDash}, ast.Space{Kind: ast.SpaceNormal},
+			ast.Word{S: "сказал"}, ast.Space{Kind: ast.SpaceNormal}, ast.Word{S: "он"},
+			ast.Punct{Ch: ','}, ast.Space{Kind: ast.SpaceNormal},
+			ast.Dash{Kind: ast.DashEmDash}, ast.Space{Kind: ast.SpaceNormal},
+			ast.Word{S: "Как"}, ast.Space{Kind: ast.SpaceNormal}, ast.Word{S: "дела"}, ast.Punct{Ch: '?'},
+		}
+		out, problems := fixRemarkPunctuation(in, true)
+		if p, ok := out[3].(ast.Punct); !ok || p.Ch != ',' {
+			t.Fatalf("expected comma before remark, got %#v", out[3])
+		}
+		if p, ok := out[10].(ast.Punct); !ok || p.Ch != '.' {
+			t.Fatalf("expected period after remark, got %#v", out[10])
+		}
+		if len(problems) != 0 {
+			t.Fatalf("unexpected problems %q", problems)
+		}
+	})
+
+	t.Run("missing comma is reported", func(t *testing.T) {
+		in := []ast.Inline{
+			ast.Dash{Kind: ast.DashEmDash}, ast.Space{Kind: ast.SpaceNormal},
+			ast.Word{S: "Привет"}, ast.Space{Kind: ast.SpaceNormal},
+			ast.Dash{Kind: ast.DashEmDash}, ast.Space{Kind: ast.SpaceNormal},
+			ast.Word{S: "сказал"}, ast.Space{Kind: ast.SpaceNormal}, ast.Word{S: "он"}, ast.Punct{Ch: '.'},
+		}
+		_, problems := fixRemarkPunctuation(in, true)
+		if len(problems) != 1 {
+			t.Fatalf("expected one problem, got %q", problems)
+		}
+	})
+
+	t.Run("quote ending with a mark is not reported", func(t *testing.T) {
+		quote := func(end ast.Inline) []ast.Inline {
+			return []ast.Inline{
+				ast.QuoteSpan{Level: ast.QuotePrimary, In: []ast.Inline{ast.Word{S: "Хорошо"}, end}},
+				ast.Space{Kind: ast.SpaceNormal},
+				ast.Dash{Kind: ast.DashEmDash}, ast.Space{Kind: ast.SpaceNormal},
+				ast.Word{S: "ответила"}, ast.Space{Kind: ast.SpaceNormal}, ast.Word{S: "она"}, ast.Punct{Ch: '.'},
+			}
+		}
+		for _, end := range []ast.Inline{ast.Punct{Ch: '!'}, ast.Punct{Ch: '?'}, ast.Punct{Ch: ','}, ast.Ellipsis{}} {
+			if _, problems := fixRemarkPunctuation(quote(end), false); len(problems) != 0 {
+				t.Fatalf("%#v: unexpected problems %q", end, problems)
+			}
+		}
+		if _, problems := fixRemarkPunctuation(quote(ast.Punct{Ch: '.'}), false); len(problems) != 1 {
+			t.Fatalf("expected one problem for a quote ending with a period, got %q", problems)
+		}
+	})
+
+	t.Run("narration dash is left alone", func(t *testing.T) {
+		in := []ast.Inline{
+			ast.Word{S: "Москва"}, ast.Space{Kind: ast.SpaceNormal},
+			ast.Dash{Kind: ast.DashEmDash}, ast.Space{Kind: ast.SpaceNormal},
+			ast.Word{S: "столица"}, ast.Punct{Ch: '.'},
+		}
+		_, problems := fixRemarkPunctuation(in, false)
+		if len(problems) != 0 {
+			t.Fatalf("unexpected problems %q", problems)
+		}
+	})
+}
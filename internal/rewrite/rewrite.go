@@ -7,10 +7,21 @@ import (
 
 func Apply(doc *ast.Document, cfg config.Config) {
 	normalizeEllipsisDocument(doc)
+	repairMixedScriptsDocument(doc, cfg.Lang)
+	if cfg.Symbols {
+		replaceSymbolsDocument(doc)
+	}
 	classifyDashesDocument(doc)
+	if cfg.SplitInlineDialogue {
+		splitInlineDialogueDocument(doc)
+	}
+	convertDialogueStyle(doc, cfg.DialogueStyle, cfg.Lang)
 	emitCanonicalPairsDocument(doc)
 	normalizeSpacingDocument(doc)
+	normalizeAbbreviationsDocument(doc, cfg.Lang)
 	normalizeDialogueBlocks(doc)
+	normalizeSpeakerTurns(doc)
+	normalizeRemarksDocument(doc, cfg.Lang)
 	if cfg.UseNBSP {
 		applyNBSPDocument(doc, cfg)
 	}
@@ -18,31 +29,7 @@ func Apply(doc *ast.Document, cfg config.Config) {
 
 func applyToAllInlines(doc *ast.Document, fn func([]ast.Inline) []ast.Inline) {
 	for i, blk := range doc.Blocks {
-		switch b := blk.(type) {
-		case ast.TitleBlock:
-			b.In = fn(b.In)
-			doc.Blocks[i] = b
-		case ast.Paragraph:
-			b.In = fn(b.In)
-			doc.Blocks[i] = b
-		case ast.Heading:
-			b.In = fn(b.In)
-			doc.Blocks[i] = b
-		case ast.ContentsBlock:
-			b.In = fn(b.In)
-			for j := range b.Entries {
-				b.Entries[j].In = fn(b.Entries[j].In)
-			}
-			doc.Blocks[i] = b
-		case ast.MetaLineBlock:
-			b.In = fn(b.In)
-			doc.Blocks[i] = b
-		case ast.DialogueBlock:
-			for j := range b.Turns {
-				b.Turns[j].In = fn(b.Turns[j].In)
-			}
-			doc.Blocks[i] = b
-		}
+		doc.Blocks[i] = ast.MapInlines(blk, fn)
 	}
 }
 
@@ -56,6 +43,9 @@ func normalizeChildren(in []ast.Inline, fn func([]ast.Inline) []ast.Inline) []as
 		case ast.ParenSpan:
 			it.In = fn(it.In)
 			out = append(out, it)
+		case ast.Emphasis:
+			it.In = fn(it.In)
+			out = append(out, it)
 		default:
 			out = append(out, item)
 		}
@@ -0,0 +1,121 @@
+package rewrite
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+	"github.com/n0madic/txtfmt/internal/config"
+)
+
+// latinToCyrillic maps Latin letters to the Cyrillic letters they can't be
+// told apart from; cyrillicToLatin is the reverse.
+var latinToCyrillic = map[rune]rune{
+	'a': 'а', 'c': 'с', 'e': 'е', 'i': 'і', 'o': 'о', 'p': 'р', 'x': 'х', 'y': 'у',
+	'A': 'А', 'B': 'В', 'C': 'С', 'E': 'Е', 'H': 'Н', 'I': 'І', 'K': 'К', 'M': 'М',
+	'O': 'О', 'P': 'Р', 'T': 'Т', 'X': 'Х', 'Y': 'У',
+}
+
+var cyrillicToLatin = func() map[rune]rune {
+	m := make(map[rune]rune, len(latinToCyrillic)+2)
+	for lat, cyr := range latinToCyrillic {
+		m[cyr] = lat
+	}
+	m['ј'] = 'j'
+	m['ѕ'] = 's'
+	return m
+}()
+
+func repairMixedScriptsDocument(doc *ast.Document, lang config.Lang) {
+	var fn func([]ast.Inline) []ast.Inline
+	fn = func(in []ast.Inline) []ast.Inline {
+		in = normalizeChildren(in, fn)
+		for i, item := range in {
+			w, ok := item.(ast.Word)
+			if !ok {
+				continue
+			}
+			fixed, mixed, repaired := repairMixedScriptWord(w.S, lang)
+			if !mixed {
+				continue
+			}
+			if repaired {
+				in[i] = ast.Word{S: fixed}
+				doc.Diags = append(doc.Diags, ast.Diag{
+					Code:    "MIXED_SCRIPT",
+					Message: fmt.Sprintf("replaced look-alike letters in %q with %q", w.S, fixed),
+				})
+				continue
+			}
+			doc.Diags = append(doc.Diags, ast.Diag{
+				Code:    "MIXED_SCRIPT",
+				Message: fmt.Sprintf("word %q mixes Latin and Cyrillic letters", w.S),
+			})
+		}
+		return in
+	}
+	applyToAllInlines(doc, fn)
+}
+
+// repairMixedScriptWord checks each hyphen-separated part of a word on its
+// own (IT-компания is fine) and converts it to the one script all of its
+// letters have look-alikes in. When both scripts would do, the part goes to
+// the script most of its letters are in, and ties go to the document
+// language.
+func repairMixedScriptWord(s string, lang config.Lang) (string, bool, bool) {
+	parts := strings.Split(s, "-")
+	mixed, repaired := false, true
+	for i, part := range parts {
+		latin, cyrillic := 0, 0
+		for _, r := range part {
+			switch {
+			case unicode.Is(unicode.Latin, r):
+				latin++
+			case unicode.Is(unicode.Cyrillic, r):
+				cyrillic++
+			}
+		}
+		if latin == 0 || cyrillic == 0 {
+			continue
+		}
+		mixed = true
+
+		asCyrillic, cyrOK := convertScript(part, unicode.Latin, latinToCyrillic)
+		asLatin, latOK := convertScript(part, unicode.Cyrillic, cyrillicToLatin)
+		switch {
+		case cyrOK && latOK:
+			if cyrillic > latin || (cyrillic == latin && lang != config.LangEN) {
+				parts[i] = asCyrillic
+			} else {
+				parts[i] = asLatin
+			}
+		case cyrOK:
+			parts[i] = asCyrillic
+		case latOK:
+			parts[i] = asLatin
+		default:
+			repaired = false
+		}
+	}
+	if !mixed || !repaired {
+		return s, mixed, false
+	}
+	return strings.Join(parts, "-"), true, true
+}
+
+func convertScript(s string, from *unicode.RangeTable, table map[rune]rune) (string, bool) {
+	var b strings.Builder
+	for _, r := range s {
+		if !unicode.Is(from, r) {
+			b.WriteRune(r)
+			continue
+		}
+		mapped, ok := table[r]
+		if !ok {
+			return s, false
+		}
+		b.WriteRune(mapped)
+	}
+	return b.String(), true
+}
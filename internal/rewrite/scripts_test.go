@@ -0,0 +1,52 @@
+package rewrite
+
+import (
+	"testing"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+	"github.com/n0madic/txtfmt/internal/config"
+)
+
+func TestRepairMixedScriptWord(t *testing.T) {
+	cases := []struct {
+		in       string
+		lang     config.Lang
+		want     string
+		mixed    bool
+		repaired bool
+	}{
+		{in: "Мoлoкo", lang: config.LangRU, want: "Молоко", mixed: true, repaired: true},
+		{in: "cмeтaнa", lang: config.LangRU, want: "сметана", mixed: true, repaired: true},
+		{in: "Sуstеm", lang: config.LangEN, want: "System", mixed: true, repaired: true},
+		{in: "IT-компания", lang: config.LangRU, want: "IT-компания"},
+		{in: "мuр", lang: config.LangRU, want: "мuр", mixed: true},
+		{in: "молоко", lang: config.LangRU, want: "молоко"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			got, mixed, repaired := repairMixedScriptWord(tc.in, tc.lang)
+			if got != tc.want || mixed != tc.mixed || repaired != tc.repaired {
+				t.Fatalf("expected %q mixed=%v repaired=%v, got %q mixed=%v repaired=%v", tc.want, tc.mixed, tc.repaired, got, mixed, repaired)
+			}
+		})
+	}
+}
+
+func TestRepairMixedScriptsReportsWords(t *testing.T) {
+	doc := ast.Document{Blocks: []ast.Block{
+		ast.Paragraph{In: []ast.Inline{
+			ast.QuoteSpan{In: []ast.Inline{ast.Word{S: "дoм"}}},
+			ast.Space{Kind: ast.SpaceNormal},
+			ast.Word{S: "мuр"},
+		}},
+	}}
+	repairMixedScriptsDocument(&doc, config.LangRU)
+
+	quote := doc.Blocks[0].(ast.Paragraph).In[0].(ast.QuoteSpan)
+	if w := quote.In[0].(ast.Word); w.S != "дом" {
+		t.Fatalf("expected word inside quotes repaired, got %q", w.S)
+	}
+	if len(doc.Diags) != 2 || doc.Diags[0].Code != "MIXED_SCRIPT" || doc.Diags[1].Code != "MIXED_SCRIPT" {
+		t.Fatalf("unexpected diags %#v", doc.Diags)
+	}
+}
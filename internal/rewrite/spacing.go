@@ -10,11 +10,16 @@ func normalizeSpacingList(in []ast.Inline) []ast.Inline {
 	in = normalizeChildren(in, normalizeSpacingList)
 
 	nonSpace := make([]ast.Inline, 0, len(in))
+	spaced := make([]bool, 0, len(in))
+	sawSpace := false
 	for _, item := range in {
 		if _, ok := item.(ast.Space); ok {
+			sawSpace = true
 			continue
 		}
 		nonSpace = append(nonSpace, item)
+		spaced = append(spaced, sawSpace)
+		sawSpace = false
 	}
 	if len(nonSpace) == 0 {
 		return nil
@@ -25,7 +30,14 @@ func normalizeSpacingList(in []ast.Inline) []ast.Inline {
 	for i := 1; i < len(nonSpace); i++ {
 		prev := out[len(out)-1]
 		cur := nonSpace[i]
-		if needSpaceBetween(prev, cur) {
+		// Verbatim text and Markdown syntax keep the source adjacency:
+		// `foo()`s stays one word.
+		if isVerbatim(prev) || isVerbatim(cur) {
+			if spaced[i] {
+				out = append(out, ast.Space{Kind: ast.SpaceNormal})
+			}
+		} else if needSpaceBetween(prev, cur) && (spaced[i] || !isEmphasis(prev) && !isEmphasis(cur)) {
+			// Emphasis may sit inside a word: un*frigging*believable.
 			out = append(out, ast.Space{Kind: ast.SpaceNormal})
 		}
 		out = append(out, cur)
@@ -33,6 +45,16 @@ func normalizeSpacingList(in []ast.Inline) []ast.Inline {
 	return out
 }
 
+func isVerbatim(in ast.Inline) bool {
+	_, ok := in.(ast.Verbatim)
+	return ok
+}
+
+func isEmphasis(in ast.Inline) bool {
+	_, ok := in.(ast.Emphasis)
+	return ok
+}
+
 func needSpaceBetween(prev, cur ast.Inline) bool {
 	if isTightRight(cur) {
 		return false
@@ -41,6 +63,13 @@ func needSpaceBetween(prev, cur ast.Inline) bool {
 		return false
 	}
 
+	if _, ok := cur.(ast.FootnoteRef); ok {
+		return false
+	}
+	if _, ok := prev.(ast.FootnoteRef); ok {
+		return startsWordLike(cur) || isEmDash(cur)
+	}
+
 	if isEmDash(cur) {
 		return true
 	}
@@ -96,7 +125,7 @@ func isEmDash(in ast.Inline) bool {
 
 func startsWordLike(in ast.Inline) bool {
 	switch in.(type) {
-	case ast.Word, ast.QuoteSpan, ast.ParenSpan:
+	case ast.Word, ast.QuoteSpan, ast.ParenSpan, ast.Emphasis, ast.Verbatim:
 		return true
 	default:
 		return false
@@ -0,0 +1,37 @@
+package rewrite
+
+import (
+	"testing"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+)
+
+func TestSpacingKeepsVerbatimAdjacency(t *testing.T) {
+	in := []ast.Inline{
+		ast.Word{S: "call"},
+		ast.Space{Kind: ast.SpaceNormal},
+		ast.Verbatim{Kind: ast.VerbatimCode, S: "`foo()`"},
+		ast.Word{S: "s"},
+	}
+	out := normalizeSpacingList(in)
+	if len(out) != 4 {
+		t.Fatalf("expected code span kept next to its suffix, got %#v", out)
+	}
+}
+
+func TestSpacingKeepsEmphasisAdjacency(t *testing.T) {
+	in := []ast.Inline{
+		ast.Word{S: "un"},
+		ast.Emphasis{Kind: ast.EmphasisItalic, Marker: "*", In: []ast.Inline{ast.Word{S: "frigging"}}},
+		ast.Word{S: "believable"},
+		ast.Space{Kind: ast.SpaceNormal},
+		ast.Punct{Ch: '.'},
+	}
+	out := normalizeSpacingList(in)
+	if len(out) != 4 {
+		t.Fatalf("expected emphasis kept inside the word, got %#v", out)
+	}
+	if _, ok := out[3].(ast.Punct); !ok {
+		t.Fatalf("expected space before period dropped, got %#v", out)
+	}
+}
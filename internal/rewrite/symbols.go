@@ -0,0 +1,226 @@
+package rewrite
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+)
+
+var (
+	dimensionsRe = regexp.MustCompile(`^(\d+)[xXхХ](\d+)$`)
+	primesRe     = regexp.MustCompile(`^(\d{1,2})['’](?:(\d{1,2})("|”|''|’’)?)?$`)
+)
+
+var vulgarFractions = map[string]string{
+	"1/2": "½",
+	"1/3": "⅓",
+	"2/3": "⅔",
+	"1/4": "¼",
+	"3/4": "¾",
+	"1/5": "⅕",
+	"2/5": "⅖",
+	"3/5": "⅗",
+	"4/5": "⅘",
+	"1/6": "⅙",
+	"5/6": "⅚",
+	"1/8": "⅛",
+	"3/8": "⅜",
+	"5/8": "⅝",
+	"7/8": "⅞",
+}
+
+var enumerationJoiners = map[string]struct{}{
+	"and": {},
+	"or":  {},
+	"и":   {},
+	"или": {},
+	"і":   {},
+	"й":   {},
+	"та":  {},
+	"або": {},
+}
+
+func replaceSymbolsDocument(doc *ast.Document) {
+	applyToAllInlines(doc, replaceSymbolsList)
+}
+
+func replaceSymbolsList(in []ast.Inline) []ast.Inline {
+	in = normalizeChildren(in, replaceSymbolsList)
+
+	out := make([]ast.Inline, 0, len(in))
+	for i := 0; i < len(in); i++ {
+		switch it := in[i].(type) {
+		case ast.ParenSpan:
+			sym, ok := parenSymbol(it)
+			if !ok {
+				break
+			}
+			attached := len(out) > 0 && !isSpace(out[len(out)-1])
+			if !acceptParenSymbol(sym, it, out, in[i+1:], attached) {
+				break
+			}
+			if attached && sym != "©" {
+				if w, ok := out[len(out)-1].(ast.Word); ok {
+					out[len(out)-1] = ast.Word{S: w.S + sym}
+					continue
+				}
+			}
+			out = append(out, ast.Word{S: sym})
+			continue
+		case ast.Dash:
+			if i+1 < len(in) && isWordText(in[i+1], ">") {
+				if len(out) > 0 && isWordText(out[len(out)-1], "<") {
+					out[len(out)-1] = ast.Word{S: "↔"}
+				} else {
+					out = append(out, ast.Word{S: "→"})
+				}
+				i++
+				continue
+			}
+			if len(out) > 0 && isWordText(out[len(out)-1], "<") {
+				out[len(out)-1] = ast.Word{S: "←"}
+				continue
+			}
+			if len(out) > 0 && isWordText(out[len(out)-1], "+") {
+				out[len(out)-1] = ast.Word{S: "±"}
+				if i+1 < len(in) && isNumericInline(in[i+1]) {
+					out[len(out)-1] = ast.Word{S: "±" + in[i+1].(ast.Word).S}
+					i++
+				}
+				continue
+			}
+		case ast.Word:
+			if frac, ok := vulgarFractionAt(in, i); ok {
+				out = append(out, ast.Word{S: frac})
+				i += 2
+				continue
+			}
+			if isMultiplicationSign(in, i) {
+				out = append(out, ast.Word{S: "×"})
+				continue
+			}
+			out = append(out, ast.Word{S: replaceWordSymbols(it.S)})
+			continue
+		}
+		out = append(out, in[i])
+	}
+	return out
+}
+
+func parenSymbol(p ast.ParenSpan) (string, bool) {
+	if p.Open != '(' || p.Close != ')' || len(p.In) != 1 {
+		return "", false
+	}
+	w, ok := p.In[0].(ast.Word)
+	if !ok {
+		return "", false
+	}
+	switch strings.ToLower(w.S) {
+	case "c", "с":
+		return "©", true
+	case "tm":
+		return "™", true
+	case "r":
+		return "®", true
+	default:
+		return "", false
+	}
+}
+
+func acceptParenSymbol(sym string, p ast.ParenSpan, before, after []ast.Inline, attached bool) bool {
+	switch sym {
+	case "™":
+		return true
+	case "®":
+		if attached {
+			return true
+		}
+		w := p.In[0].(ast.Word)
+		return w.S == "R"
+	}
+
+	// "(c)" doubles as an enumeration item: "(a), (b) and (c)".
+	if idx := prevNonSpaceIndex(before, len(before)); idx >= 0 {
+		switch prev := before[idx].(type) {
+		case ast.ParenSpan:
+			return false
+		case ast.Punct:
+			if prev.Ch == ',' {
+				return false
+			}
+		case ast.Word:
+			if _, ok := enumerationJoiners[strings.ToLower(prev.S)]; ok {
+				return false
+			}
+		}
+	}
+
+	idx := nextNonSpaceIndex(after, -1)
+	if idx < 0 {
+		return false
+	}
+	w, ok := after[idx].(ast.Word)
+	if !ok || w.S == "" {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(w.S)
+	return unicode.IsDigit(r) || unicode.IsUpper(r)
+}
+
+func vulgarFractionAt(in []ast.Inline, i int) (string, bool) {
+	if i+2 >= len(in) || !isWordText(in[i+1], "/") {
+		return "", false
+	}
+	num, ok1 := in[i].(ast.Word)
+	den, ok2 := in[i+2].(ast.Word)
+	if !ok1 || !ok2 {
+		return "", false
+	}
+	// Dates and paths like 1/2/2020 must stay intact.
+	if i > 0 && isWordText(in[i-1], "/") {
+		return "", false
+	}
+	if i+3 < len(in) && isWordText(in[i+3], "/") {
+		return "", false
+	}
+	frac, ok := vulgarFractions[num.S+"/"+den.S]
+	return frac, ok
+}
+
+func isMultiplicationSign(in []ast.Inline, i int) bool {
+	w, ok := in[i].(ast.Word)
+	if !ok {
+		return false
+	}
+	switch w.S {
+	case "x", "х":
+	default:
+		return false
+	}
+	if i < 2 || i+2 >= len(in) || !isSpace(in[i-1]) || !isSpace(in[i+1]) {
+		return false
+	}
+	return isNumericInline(in[i-2]) && isNumericInline(in[i+2])
+}
+
+func replaceWordSymbols(s string) string {
+	if m := dimensionsRe.FindStringSubmatch(s); m != nil {
+		return m[1] + "×" + m[2]
+	}
+	if m := primesRe.FindStringSubmatch(s); m != nil {
+		out := m[1] + "′" + m[2]
+		if m[3] != "" {
+			out += "″"
+		}
+		return out
+	}
+	return s
+}
+
+func isWordText(in ast.Inline, s string) bool {
+	w, ok := in.(ast.Word)
+	return ok && w.S == s
+}
@@ -0,0 +1,107 @@
+package rewrite
+
+import (
+	"testing"
+
+	"github.com/n0madic/txtfmt/internal/ast"
+)
+
+func TestSymbolReplacements(t *testing.T) {
+	t.Run("copyright before year", func(t *testing.T) {
+		in := []ast.Inline{
+			ast.ParenSpan{Open: '(', Close: ')', In: []ast.Inline{ast.Word{S: "c"}}},
+			ast.Space{Kind: ast.SpaceNormal},
+			ast.Word{S: "2020"},
+		}
+		out := replaceSymbolsList(in)
+		if w, ok := out[0].(ast.Word); !ok || w.S != "©" {
+			t.Fatalf("expected ©, got %#v", out[0])
+		}
+	})
+
+	t.Run("enumeration stays paren", func(t *testing.T) {
+		in := []ast.Inline{
+			ast.Word{S: "and"},
+			ast.Space{Kind: ast.SpaceNormal},
+			ast.ParenSpan{Open: '(', Close: ')', In: []ast.Inline{ast.Word{S: "c"}}},
+			ast.Space{Kind: ast.SpaceNormal},
+			ast.Word{S: "Then"},
+		}
+		out := replaceSymbolsList(in)
+		if _, ok := out[2].(ast.ParenSpan); !ok {
+			t.Fatalf("expected ParenSpan, got %#v", out[2])
+		}
+	})
+
+	t.Run("trademark attaches to word", func(t *testing.T) {
+		in := []ast.Inline{
+			ast.Word{S: "Brand"},
+			ast.ParenSpan{Open: '(', Close: ')', In: []ast.Inline{ast.Word{S: "tm"}}},
+		}
+		out := replaceSymbolsList(in)
+		if len(out) != 1 {
+			t.Fatalf("expected single word, got %#v", out)
+		}
+		if w, ok := out[0].(ast.Word); !ok || w.S != "Brand™" {
+			t.Fatalf("expected Brand™, got %#v", out[0])
+		}
+	})
+
+	t.Run("plus minus", func(t *testing.T) {
+		in := []ast.Inline{ast.Word{S: "+"}, ast.Dash{Kind: ast.DashHyphen}, ast.Word{S: "5"}}
+		out := replaceSymbolsList(in)
+		if len(out) != 1 {
+			t.Fatalf("expected single word, got %#v", out)
+		}
+		if w, ok := out[0].(ast.Word); !ok || w.S != "±5" {
+			t.Fatalf("expected ±5, got %#v", out[0])
+		}
+	})
+
+	t.Run("arrow", func(t *testing.T) {
+		in := []ast.Inline{ast.Dash{Kind: ast.DashHyphen}, ast.Word{S: ">"}}
+		out := replaceSymbolsList(in)
+		if len(out) != 1 {
+			t.Fatalf("expected single word, got %#v", out)
+		}
+		if w, ok := out[0].(ast.Word); !ok || w.S != "→" {
+			t.Fatalf("expected →, got %#v", out[0])
+		}
+	})
+
+	t.Run("fraction", func(t *testing.T) {
+		in := []ast.Inline{ast.Word{S: "1"}, ast.Word{S: "/"}, ast.Word{S: "2"}}
+		out := replaceSymbolsList(in)
+		if len(out) != 1 {
+			t.Fatalf("expected single word, got %#v", out)
+		}
+		if w, ok := out[0].(ast.Word); !ok || w.S != "½" {
+			t.Fatalf("expected ½, got %#v", out[0])
+		}
+	})
+
+	t.Run("date is not a fraction", func(t *testing.T) {
+		in := []ast.Inline{
+			ast.Word{S: "1"}, ast.Word{S: "/"}, ast.Word{S: "2"}, ast.Word{S: "/"}, ast.Word{S: "2020"},
+		}
+		out := replaceSymbolsList(in)
+		if len(out) != len(in) {
+			t.Fatalf("expected date to stay intact, got %#v", out)
+		}
+	})
+
+	t.Run("word level", func(t *testing.T) {
+		cases := map[string]string{
+			"3x4":    "3×4",
+			`5'10"`:  "5′10″",
+			"6'":     "6′",
+			"1990’s": "1990’s",
+			"box":    "box",
+		}
+		for in, want := range cases {
+			if got := replaceWordSymbols(in); got != want {
+				t.Fatalf("replaceWordSymbols(%q)=%q want %q", in, got, want)
+			}
+		}
+	})
+}